@@ -2,13 +2,17 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/base64"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/silogen/cluster-bloom/pkg/ansible/runtime"
 	"github.com/silogen/cluster-bloom/pkg/config"
+	"github.com/silogen/cluster-bloom/pkg/ssh"
 	"github.com/silogen/cluster-bloom/pkg/webui"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -28,6 +32,21 @@ var (
 	export          bool
 	showVersion     bool
 	clusterListenIP string
+	eventsJSON      string
+	assumeYes       bool
+	wipeDisks       bool
+	dumpInventory   string
+	retries         int
+	expectedNodes   int
+	waitTimeout     time.Duration
+	kubeconfigPath  string
+	skipLonghorn    bool
+	outputFormat    string
+	resume          bool
+	onlyCategory    string
+	reuseSSHKey     bool
+	bindAddr        string
+	allowCIDRs      []string
 )
 
 func init() {
@@ -108,6 +127,8 @@ func newRootCmd() *cobra.Command {
 Certificate Updates:
   To update TLS certificates in an existing cluster, use a separate config with --tags:
     bloom cli cert-update-config.yaml --tags update_cert
+  To regenerate a self-signed certificate (CERT_OPTION=generate):
+    bloom cert renew --config bloom.yaml
   See 'bloom cli --help' for details.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			if showVersion {
@@ -148,8 +169,9 @@ Certificate Updates:
 	}
 
 	cleanupCmd := &cobra.Command{
-		Use:   "cleanup [config-file]",
-		Short: "Clean up existing Bloom cluster installation",
+		Use:     "cleanup [config-file]",
+		Aliases: []string{"uninstall"},
+		Short:   "Clean up existing Bloom cluster installation",
 		Long: `Removes RKE2 services, Longhorn mounts, and managed disks from previous Bloom installations.
 
 This command performs the full cluster teardown sequence:
@@ -176,7 +198,14 @@ Mount index allocation is fstab- and config-aware: the lowest contiguous range s
 from index 0 that does not conflict with premounted disk indexes is chosen, ensuring
 CLUSTER_DISKS and CLUSTER_PREMOUNTED_DISKS can coexist without collision.
 
-By default, this command requires confirmation before proceeding. Use --force to skip confirmation.`,
+Every step runs even if an earlier one fails, and a per-step summary is printed
+at the end. 'bloom uninstall' is an alias for this command.
+
+With --dry-run nothing is executed: after the preview, bloom lists the mounts it
+would unmount, the fstab and crypttab lines it would remove, the LUKS mappings it
+would close, the RKE2 directories it would delete and the devices it would wipe.
+
+By default, this command requires confirmation before proceeding. Use --yes (or --force) to skip confirmation.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			checkRootPrivileges("cleanup")
 			// Load config early so the preview can use it before confirmation
@@ -205,8 +234,13 @@ By default, this command requires confirmation before proceeding. Use --force to
 			}
 			// Show disk wipe preview before asking for confirmation
 			runtime.PrintDiskWipePreview(clusterDisks, premountedDisks, rancherDisk)
+			if dryRun {
+				runtime.PlanCleanup(clusterDisks).Print()
+				fmt.Println("🔎 Dry run: nothing was cleaned up.")
+				return
+			}
 			// Check if force flag is used to bypass confirmation
-			if !forceCleanup {
+			if !forceCleanup && !assumeYes {
 				if !confirmCleanupOperation() {
 					fmt.Println("❌ Cleanup aborted by user.")
 					os.Exit(0)
@@ -240,10 +274,36 @@ Export Mode:
   instead of executing it. The directory contains the root playbook, a bloom-vars.yaml
   file derived from your config, and the tasks/ and manifests/ trees. Run it with:
     ansible-playbook bloom-playbook/cluster-bloom.yaml
-  Example: ./bloom cli bloom.yaml --export`,
+  Example: ./bloom cli bloom.yaml --export
+
+JSON Output:
+  Use --output json to write one JSON object per task to stdout (id, name, status,
+  duration_ms, message, error) for deployment automation. Bloom's own messages go
+  to stderr; bloom.log is unchanged.
+  Example: sudo ./bloom cli bloom.yaml --output json > results.ndjson
+
+Resume:
+  Use --resume after fixing the cause of a failed deployment to skip the phases
+  it completed (data safety check and node preparation, then the RKE2 install),
+  as recorded in bloom.log. Node validation and the failed phase run again. Bloom
+  refuses to resume if node, disk or RKE2 settings changed since that run.
+  Example: sudo ./bloom cli bloom.yaml --resume
+
+Only Category:
+  Use --only-category to run a single deployment phase, e.g. to debug disk and
+  ROCm preparation without bringing up the cluster. Categories: pre-k8s (data
+  safety check, node validation and preparation), k8s (RKE2 install and cluster
+  bootstrap) and post-k8s (cluster applications and ClusterForge). Each phase
+  expects the earlier ones to have completed on this node.
+  Example: sudo ./bloom cli bloom.yaml --only-category pre-k8s
+
+Inventory Dump:
+  Use --dump-inventory <file> to write the inventory bloom uses (the local node over
+  SSH with become) and exit, e.g. to debug connection issues with:
+    ansible-playbook -i inventory.ini bloom-playbook/cluster-bloom.yaml`,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			if !export {
+			if !export && dumpInventory == "" {
 				checkRootPrivileges("cli")
 			}
 			runAnsible(args[0])
@@ -265,27 +325,334 @@ imports (roles, tasks, vars) within that directory tree work as expected.`,
 		},
 	}
 
+	certCmd := &cobra.Command{
+		Use:   "cert",
+		Short: "Manage cluster certificates",
+		Long:  `Day-2 certificate management for an existing Bloom cluster.`,
+	}
+
+	certRenewCmd := &cobra.Command{
+		Use:   "renew",
+		Short: "Regenerate the self-signed domain certificate",
+		Long: `Regenerate the self-signed domain certificate on the first node and roll it out.
+
+This command:
+  1. Regenerates the certificate and key in /etc/rancher/rke2/certs (same SANs as
+     the original install, including ADDITIONAL_TLS_SAN_URLS and API_SERVER_SANS)
+  2. Updates the cluster-tls secret in envoy-gateway-system
+  3. Restarts the Envoy Gateway pods (unless RESTART_ENVOY_PODS: false)
+
+Only applies to configs with FIRST_NODE: true, USE_CERT_MANAGER: false and
+CERT_OPTION: generate. For user-provided certificates use --tags update_cert.
+
+Example:
+  sudo bloom cert renew --config bloom.yaml`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			checkRootPrivileges("cert renew")
+			runCertRenew(configFile)
+		},
+	}
+
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Re-apply post-Kubernetes application steps to a running cluster",
+		Long: `Re-run only the steps that run after RKE2 is up, without touching disks,
+packages or the RKE2 installation.
+
+This command:
+  1. Verifies rke2-server is active and the API server is ready
+  2. Re-applies the Kubernetes applications (node annotator, MetalLB,
+     local-path/Longhorn, domain config and TLS secret, bloom ConfigMap)
+  3. Re-runs the ClusterForge deployment (unless CLUSTERFORGE_RELEASE is none)
+
+Must run on the first node with the config the cluster was deployed with.
+
+Example:
+  sudo bloom apply --config bloom.yaml`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			checkRootPrivileges("apply")
+			runApply(configFile)
+		},
+	}
+
+	dnsCheckCmd := &cobra.Command{
+		Use:   "dnscheck",
+		Short: "Verify cluster DNS resolution from inside a pod",
+		Long: `Run the cluster DNS smoke test against a running cluster.
+
+This command:
+  1. Verifies rke2-server is active and the API server is ready
+  2. Starts a short-lived busybox pod in the default namespace
+  3. Resolves kubernetes.default and DNS_CHECK_EXTERNAL_NAME from the pod
+  4. Removes the pod and reports which lookup failed, if any
+
+Must run on the first node. The same check runs at the end of a deployment
+when DNS_CHECK: true.
+
+Example:
+  sudo bloom dnscheck --config bloom.yaml`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			checkRootPrivileges("dnscheck")
+			runDNSCheck(configFile)
+		},
+	}
+
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate a configuration file without installing anything",
+		Long: `Run every configuration check bloom performs before a deployment and exit.
+
+Reports, grouped by category:
+  - Errors: unknown or removed keys, missing required fields, invalid formats,
+    conflicting settings and unsupported GPU stack combinations
+  - Warnings: contradictory but non-fatal settings
+
+Exits non-zero if any error is found. Does not require root and never touches
+the machine, so it can lint bloom.yaml in CI before shipping it to nodes.
+
+Example:
+  bloom validate --config bloom.yaml`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runValidate(configFile)
+		},
+	}
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check that every component bloom installed is present",
+		Long: `Run read-only checks for the components bloom installed for this config
+and report pass/fail per component with a remediation hint.
+
+Checks (each only when the config installs it):
+  - RKE2 service (rke2-server on first/control-plane nodes, rke2-agent on workers)
+  - Kubeconfig and API server readiness
+  - Default storage class (local-path or Longhorn, by CLUSTER_SIZE)
+  - MetalLB address pool
+  - Domain ConfigMap and TLS secret (when DOMAIN is set)
+  - Bloom ConfigMap
+  - ClusterForge application (when CLUSTERFORGE_RELEASE is set)
+
+Nothing on the node or cluster is changed. Exits non-zero if any check fails.
+
+Example:
+  sudo bloom verify --config bloom.yaml`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			checkRootPrivileges("verify")
+			runVerify(configFile)
+		},
+	}
+
+	waitCmd := &cobra.Command{
+		Use:   "wait",
+		Short: "Wait until the cluster is fully ready",
+		Long: `Block until the cluster has the expected number of Ready nodes and its core
+components are available, then exit 0. Exits non-zero if --timeout elapses first.
+
+Waits for:
+  - --expected-nodes nodes in the Ready condition
+  - CoreDNS deployment available
+  - CNI agent DaemonSet (cilium, canal, calico or flannel) available on every node
+  - Longhorn manager DaemonSet available on every node (skip with --skip-longhorn)
+
+Runs anywhere kubectl can reach the cluster: uses --kubeconfig, else KUBECONFIG
+or ~/.kube/config, else the RKE2 kubeconfig on a cluster node. Use it after a
+distributed deploy to gate application deployment.
+
+Example:
+  bloom wait --expected-nodes 3 --timeout 30m`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runWait()
+		},
+	}
+
+	disksCmd := &cobra.Command{
+		Use:   "disks",
+		Short: "Manage bloom-managed storage disks",
+		Long:  `Day-2 storage management for a Bloom node.`,
+	}
+
+	disksTeardownCmd := &cobra.Command{
+		Use:   "teardown",
+		Short: "Release bloom-managed CLUSTER_DISKS without uninstalling RKE2",
+		Long: `Unmount the bloom-managed CLUSTER_DISKS mounts on this node and remove their
+fstab entries, leaving RKE2 and the rest of the cluster installed.
+
+This command:
+  1. Lists the fstab entries carrying the "# managed by cluster-bloom" marker,
+     restricted to CLUSTER_DISKS when it is set in the config
+  2. Backs up /etc/fstab, unmounts each mount point and removes its entry
+  3. Wipes the devices with wipefs (only with --wipe)
+
+CLUSTER_PREMOUNTED_DISKS and RANCHER_DISK are never touched. Remove the disks
+from Longhorn first, otherwise Longhorn will report them as failed.
+
+Example:
+  sudo bloom disks teardown --config bloom.yaml --wipe`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			checkRootPrivileges("disks teardown")
+			runDisksTeardown(configFile)
+		},
+	}
+
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect bloom configuration files",
+		Long:  `Tools for working with bloom.yaml outside of a deployment.`,
+	}
+
+	configDiffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare a bloom.yaml against the config the cluster was deployed with",
+		Long: `Compare a local bloom.yaml against the config stored in the cluster's bloom
+ConfigMap (default namespace) and print the keys that were added, removed or
+changed since the last deploy or apply.
+
+Token-like values (JOIN_TOKEN, DOCKERHUB_TOKEN, client secrets, ...) and
+passwords in URLs such as HTTP_PROXY are never printed or stored in the
+cluster; a short fingerprint shows whether they changed.
+Clusters deployed before the full config was stored in the ConfigMap are only
+compared on DOMAIN, CLUSTER_SIZE, GPU_NODE and RKE2_VERSION.
+
+Exits 0 when the configs match, 1 when they differ and 2 on errors, so it can
+gate a re-apply or detect drift in CI.
+
+Example:
+  bloom config diff --config bloom.yaml`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runConfigDiff(configFile)
+		},
+	}
+
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Collect logs and node state into a diagnostics tarball for bug reports",
+		Long: `Collect what support needs to debug a failed install into
+bloom-diagnostics-<timestamp>.tar.gz in the current directory:
+
+  - bloom.log, the archived bloom-*.log of earlier runs and bloom-summary.json
+  - /etc/rancher/rke2/config.yaml
+  - journalctl output of the rke2-server and rke2-agent units
+  - lsblk and mount snapshots
+  - rocm-smi output on GPU nodes
+  - the bloom config given with --config, redacted as in bloom config diff
+
+Tokens and passwords (JOIN_TOKEN, the RKE2 token and agent-token,
+ONEPASSWORD_CONNECT_TOKEN, DOCKERHUB_TOKEN, passwords in proxy URLs, ...) are
+replaced by <redacted> in every file. Sources that cannot be read are listed
+in MISSING.txt. Run it from the directory bloom was run in.
+
+Example:
+  sudo bloom doctor --config bloom.yaml`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			checkRootPrivileges("doctor")
+			runDoctor(configFile)
+		},
+	}
+
+	sshCleanupCmd := &cobra.Command{
+		Use:   "ssh-cleanup",
+		Short: "Remove the temporary SSH key an interrupted bloom run left in authorized_keys",
+		Long: `Bloom reaches the local node through a temporary SSH key that it adds to the
+sudo user's authorized_keys and removes when the run ends. A killed run can
+leave it behind, and the next run then stops with "Bloom ephemeral key already
+exists in authorized_keys".
+
+This command removes every line marked '# bloom-ephemeral-key' or
+'bloom-ephemeral@localhost'. When an authorized_keys.backup.* file without bloom
+keys exists, the newest one is restored; otherwise only the bloom lines are
+dropped. The key files in ./ssh are deleted too, so run it from the directory
+bloom was run in. With --dry-run it only lists what would be removed.
+
+Example:
+  sudo bloom ssh-cleanup`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			checkRootPrivileges("ssh-cleanup")
+			runSSHCleanup()
+		},
+	}
+
 	// Add flags
 	rootCmd.PersistentFlags().IntVarP(&port, "port", "p", 62078, "Port for web UI (fails if in use)")
+	rootCmd.PersistentFlags().StringVar(&bindAddr, "bind-addr", webui.DefaultBindAddr, "IP address the web UI listens on; a non-loopback address also needs --allow-cidrs for remote clients")
+	rootCmd.PersistentFlags().StringSliceVar(&allowCIDRs, "allow-cidrs", nil, "Networks allowed to use the web UI besides localhost, comma-separated (e.g. 10.0.0.0/8); only useful with --bind-addr")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Preview without making changes: playbooks run in check mode and destructive commands only show what they would touch")
 	rootCmd.Flags().BoolVarP(&showVersion, "version", "v", false, "Show version information")
 
 	// Add CLI command flags
 	cliCmd.Flags().StringVar(&playbookName, "playbook", "cluster-bloom.yaml", "Playbook to run (default: cluster-bloom.yaml)")
-	cliCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Run in check mode without making changes")
 	cliCmd.Flags().StringVar(&tags, "tags", "", "Run only tasks with specific tags (e.g., cleanup, validate, storage)")
 	cliCmd.Flags().BoolVar(&destroyData, "destroy-data", false, "⚠️  DANGER: Wipes cluster (RKE2 uninstall, Longhorn cleanup, disk wipe). Shows disk preview before confirmation. Equivalent to running bloom cleanup then redeploying.")
 	cliCmd.Flags().StringVar(&clusterListenIP, "cluster-listen-ip", "", "IP address or CIDR for cluster binding (e.g., 192.168.1.100 or 192.168.1.0/24)")
 	cliCmd.Flags().BoolVar(&export, "export", false, "Export the playbook to ./bloom-playbook/ (overwrites if exists) instead of executing it")
+	cliCmd.Flags().StringVar(&dumpInventory, "dump-inventory", "", "Write the ansible inventory bloom would use to this file and exit (for running ansible-playbook manually)")
+	cliCmd.Flags().IntVar(&retries, "retries", 0, "Re-run the whole deployment up to N times after a transient failure, with increasing backoff (node validation failures are not retried)")
+	cliCmd.Flags().StringVar(&onlyCategory, "only-category", "", "Run only one deployment phase: pre-k8s (node validation and preparation: disks, ROCm), k8s (RKE2 install) or post-k8s (cluster applications, ClusterForge)")
+	cliCmd.Flags().BoolVar(&resume, "resume", false, "Skip the phases (node preparation, RKE2 install) the previous failed run completed, according to bloom.log; refused if key config values changed")
+	cliCmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text (emoji summary per task) or json (one JSON object per task on stdout; other messages go to stderr)")
+	cliCmd.Flags().StringVar(&eventsJSON, "events-json", "", "Also write newline-delimited JSON progress events to this file, named pipe or /dev/fd/N (for TUI clients)")
+	cliCmd.Flags().BoolVar(&reuseSSHKey, "reuse-ssh-key", false, "Reuse the ephemeral SSH key an interrupted run left in authorized_keys (or replace it if its key files are gone) instead of failing")
 
 	// Add run command flags
-	runCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Run in check mode without making changes")
 	runCmd.Flags().StringVar(&tags, "tags", "", "Run only tasks with specific tags")
 	runCmd.Flags().StringArrayVarP(&extraVars, "extra-vars", "e", nil, "Extra variables passed to ansible-playbook (repeatable)")
-	runCmd.Flags().StringVarP(&configFile, "config", "c", "", "YAML config file whose keys become ansible extra vars")
+	runCmd.Flags().StringVarP(&configFile, "config", "c", "", "YAML or JSON config file whose keys become ansible extra vars")
 	runCmd.Flags().BoolVar(&verbose, "verbose", false, "Show full Ansible output instead of clean summary")
+	runCmd.Flags().BoolVar(&reuseSSHKey, "reuse-ssh-key", false, "Reuse the ephemeral SSH key an interrupted run left in authorized_keys (or replace it if its key files are gone) instead of failing")
+
+	// Add apply flags
+	applyCmd.Flags().StringVarP(&configFile, "config", "c", "", "Bloom config file the cluster was deployed with (typically bloom.yaml)")
+	applyCmd.MarkFlagRequired("config")
+
+	// Add cert renew flags
+	certRenewCmd.Flags().StringVarP(&configFile, "config", "c", "", "Bloom config file (typically bloom.yaml)")
+	certRenewCmd.MarkFlagRequired("config")
+
+	// Add dnscheck flags
+	dnsCheckCmd.Flags().StringVarP(&configFile, "config", "c", "", "Bloom config file the cluster was deployed with (typically bloom.yaml)")
+	dnsCheckCmd.MarkFlagRequired("config")
+
+	// Add validate flags
+	validateCmd.Flags().StringVarP(&configFile, "config", "c", "", "Bloom config file to validate (typically bloom.yaml)")
+	validateCmd.MarkFlagRequired("config")
+
+	// Add verify flags
+	verifyCmd.Flags().StringVarP(&configFile, "config", "c", "", "Bloom config file the node was deployed with (typically bloom.yaml)")
+	verifyCmd.MarkFlagRequired("config")
+
+	// Add wait flags
+	waitCmd.Flags().IntVarP(&expectedNodes, "expected-nodes", "n", 0, "Number of nodes that must be Ready")
+	waitCmd.Flags().DurationVarP(&waitTimeout, "timeout", "t", 30*time.Minute, "Give up after this long (e.g. 45m)")
+	waitCmd.Flags().StringVar(&kubeconfigPath, "kubeconfig", "", "Kubeconfig to use (default: KUBECONFIG, ~/.kube/config or the RKE2 kubeconfig)")
+	waitCmd.Flags().BoolVar(&skipLonghorn, "skip-longhorn", false, "Do not wait for Longhorn (clusters without Longhorn storage)")
+	waitCmd.MarkFlagRequired("expected-nodes")
+
+	// Add disks teardown flags
+	disksTeardownCmd.Flags().StringVarP(&configFile, "config", "c", "", "Bloom config file (typically bloom.yaml)")
+	disksTeardownCmd.Flags().BoolVar(&assumeYes, "yes", false, "Skip the confirmation prompt")
+	disksTeardownCmd.Flags().BoolVar(&wipeDisks, "wipe", false, "Also wipe filesystem signatures from the released devices (destroys their data)")
+	disksTeardownCmd.MarkFlagRequired("config")
+
+	// Add config diff flags
+	configDiffCmd.Flags().StringVarP(&configFile, "config", "c", "", "Local bloom config file to compare (typically bloom.yaml)")
+	configDiffCmd.Flags().StringVar(&kubeconfigPath, "kubeconfig", "", "Kubeconfig to use (default: KUBECONFIG, ~/.kube/config or the RKE2 kubeconfig)")
+	configDiffCmd.MarkFlagRequired("config")
+
+	// Add doctor flags
+	doctorCmd.Flags().StringVarP(&configFile, "config", "c", "", "Bloom config file the node was deployed with; adds it (redacted) to the bundle and scrubs its secrets from the logs")
 
 	// Add cleanup-specific flags
 	cleanupCmd.Flags().BoolVarP(&forceCleanup, "force", "f", false, "Skip confirmation prompt and force immediate cleanup (USE WITH CAUTION)")
+	cleanupCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Skip the confirmation prompt (same as --force)")
 
 	// Add subcommands
 	rootCmd.AddCommand(webuiCmd)
@@ -293,6 +660,19 @@ imports (roles, tasks, vars) within that directory tree work as expected.`,
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(cleanupCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(dnsCheckCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(waitCmd)
+	certCmd.AddCommand(certRenewCmd)
+	rootCmd.AddCommand(certCmd)
+	disksCmd.AddCommand(disksTeardownCmd)
+	rootCmd.AddCommand(disksCmd)
+	configCmd.AddCommand(configDiffCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(sshCleanupCmd)
 
 	return rootCmd
 }
@@ -300,11 +680,17 @@ imports (roles, tasks, vars) within that directory tree work as expected.`,
 func runWebUI(cmd *cobra.Command) {
 	portSpecified := cmd.Flags().Changed("port")
 
-	server := &webui.Server{Port: port, PortSpecified: portSpecified}
+	server := &webui.Server{Port: port, PortSpecified: portSpecified, BindAddr: bindAddr, AllowCIDRs: allowCIDRs}
+	// Read from the environment, not a flag, to keep the token out of ps output
+	server.AuthToken = os.Getenv("WEBUI_AUTH_TOKEN")
 	if err := server.Start(); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to start web UI: %v\n", err)
 		os.Exit(1)
 	}
+
+	if server.InstallConfig != "" {
+		runAnsible(server.InstallConfig)
+	}
 }
 
 func runAnsible(configFile string) {
@@ -332,6 +718,8 @@ func runAnsible(configFile string) {
 			os.Exit(1)
 		}
 	}
+	printConfigWarnings(cfg)
+	storeConfigSnapshot(cfg)
 
 	// Resolve GPU-family stack defaults (host ROCm + GPU Operator + DeviceConfig)
 	// and inject them as ansible vars before export/run.
@@ -340,6 +728,46 @@ func runAnsible(configFile string) {
 		os.Exit(1)
 	}
 
+	if retries < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --retries must be 0 or greater")
+		os.Exit(1)
+	}
+	if resume && (destroyData || tags != "") {
+		fmt.Fprintln(os.Stderr, "Error: --resume cannot be combined with --destroy-data or --tags")
+		os.Exit(1)
+	}
+	if onlyCategory != "" {
+		if tags != "" || resume {
+			fmt.Fprintln(os.Stderr, "Error: --only-category cannot be combined with --tags or --resume")
+			os.Exit(1)
+		}
+		categoryTags, err := runtime.CategoryTags(onlyCategory)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --only-category: %v\n", err)
+			os.Exit(1)
+		}
+		tags = categoryTags
+	}
+	if outputFormat != "text" && outputFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Error: --output must be text or json, got %q\n", outputFormat)
+		os.Exit(1)
+	}
+	if outputFormat == "json" && !export && dumpInventory == "" {
+		// Keep stdout for the task results from here on
+		runtime.EnableJSONOutput()
+	}
+
+	// Handle inventory dump
+	if dumpInventory != "" {
+		if err := runtime.WriteInventory(dumpInventory); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Inventory written to %s\n", dumpInventory)
+		fmt.Printf("   ansible-playbook -i %s <playbook>\n", dumpInventory)
+		return
+	}
+
 	// Handle export mode
 	if export {
 		if destroyData {
@@ -353,8 +781,35 @@ func runAnsible(configFile string) {
 		return
 	}
 
-	// Handle destructive data cleanup if requested
-	if destroyData {
+	// Keep the pod and service networks clear of the node's own networks; an
+	// exported playbook runs elsewhere, so this only applies to a local run
+	if tags == "" || !strings.Contains(tags, "update_cert") {
+		hostNets, err := config.HostNetworks()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		changes, err := config.ResolveClusterCIDRs(cfg, hostNets)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, change := range changes {
+			fmt.Printf("🔀 AUTO_CIDR: %s\n", change)
+		}
+		if len(changes) > 0 {
+			// The bloom ConfigMap must record the ranges the cluster really uses
+			storeConfigSnapshot(cfg)
+		}
+	}
+
+	// Handle destructive data cleanup if requested. Check mode cannot undo a
+	// wipe, so a dry run only reports that the cleanup was skipped.
+	if destroyData && dryRun {
+		clusterDisks, _ := cfg["CLUSTER_DISKS"].(string)
+		runtime.PlanCleanup(clusterDisks).Print()
+		fmt.Println("🔎 Dry run: skipping the --destroy-data cleanup; no data will be touched.")
+	} else if destroyData {
 		if !confirmDestructiveOperation(cfg) {
 			fmt.Println("\n❌ Operation aborted by user. No data was harmed.")
 			os.Exit(0)
@@ -362,11 +817,46 @@ func runAnsible(configFile string) {
 		runClusterCleanup(cfg)
 	}
 
+	// Skip the phases the previous run completed, as recorded in bloom.log
+	if resume {
+		plan, err := runtime.PlanResume(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: cannot resume: %v\n", err)
+			os.Exit(1)
+		}
+		printResumePlan(plan)
+		cfg["bloom_resume_skip"] = plan.Skip
+	}
+	if tags == "" {
+		// Checkpoint for a later --resume
+		cfg["bloom_resume_keys"] = runtime.ResumeKeys(cfg)
+	}
+
 	// Use clean (terse/emoji) output mode by default
 	mode := runtime.OutputClean
+	if outputFormat == "json" {
+		mode = runtime.OutputJSON
+	}
 
-	// Run the playbook
+	// Stream structured progress events for external clients if requested
+	runtime.SetEventsOutput(eventsJSON)
+	runtime.SetReuseSSHKey(reuseSSHKey)
+	requireEntropy, _ := cfg["REQUIRE_ENTROPY"].(bool)
+	runtime.SetRequireEntropy(requireEntropy)
+
+	// Run the playbook, re-running it after transient failures when --retries
+	// is set. Each run archives the previous bloom.log before starting.
 	exitCode, err := runtime.RunPlaybook(cfg, playbookName, dryRun, tags, mode, Version)
+	for attempt := 1; err == nil && exitCode != 0 && attempt <= retries && !dryRun; attempt++ {
+		if retryable, reason := runtime.RetryableFailure(exitCode); !retryable {
+			fmt.Fprintf(os.Stderr, "Not retrying: %s\n", reason)
+			break
+		}
+		delay := time.Duration(attempt) * retryBackoff
+		fmt.Printf("\n🔁 Deployment failed (exit code %d); retrying in %s (retry %d of %d)...\n", exitCode, delay, attempt, retries)
+		time.Sleep(delay)
+		exitCode, err = runtime.RunPlaybook(cfg, playbookName, dryRun, tags, mode, Version)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -375,6 +865,415 @@ func runAnsible(configFile string) {
 	os.Exit(exitCode)
 }
 
+// resumePhaseSteps names what skipping each phase leaves out
+var resumePhaseSteps = map[string]string{
+	"preK8s": "data safety check, node preparation",
+	"k8s":    "RKE2 install and cluster bootstrap",
+}
+
+// printResumePlan reports which phases a --resume run skips
+func printResumePlan(plan runtime.ResumePlan) {
+	if plan.FailedPhase == "" {
+		fmt.Println("⏩ Resume: the previous run stopped before its first phase; no completed phases to skip")
+		return
+	}
+	if len(plan.Skip) == 0 {
+		fmt.Printf("⏩ Resume: the previous run failed in the %s phase; no completed phases to skip\n", plan.FailedPhase)
+		return
+	}
+	fmt.Printf("⏩ Resume: the previous run failed in the %s phase; skipping completed phases:\n", plan.FailedPhase)
+	for _, phase := range plan.Skip {
+		fmt.Printf("   - %s (%s)\n", phase, resumePhaseSteps[phase])
+	}
+	fmt.Println("   Node validation and every later phase run as usual.")
+}
+
+// retryBackoff is the delay before the first --retries re-run; each further
+// retry waits one more multiple of it
+const retryBackoff = 30 * time.Second
+
+// loadValidConfig loads and validates a config file, exiting on any error
+func loadValidConfig(configFile string) config.Config {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	if errors := config.Validate(cfg); len(errors) > 0 {
+		fmt.Fprintln(os.Stderr, "Configuration validation errors:")
+		for _, err := range errors {
+			fmt.Fprintf(os.Stderr, "  - %s\n", err)
+		}
+		os.Exit(1)
+	}
+	printConfigWarnings(cfg)
+
+	return cfg
+}
+
+// runValidate runs all configuration checks against configFile and prints
+// the errors and warnings grouped by category, exiting 1 on any error
+func runValidate(configFile string) {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	errors := config.Validate(cfg)
+	warnings := config.Warnings(cfg)
+
+	// Unsupported GPU stack combinations are otherwise only caught when the
+	// stack defaults are resolved right before a deployment
+	var stackErrors []string
+	if len(errors) == 0 {
+		if err := config.ApplyGPUStackVars(cfg); err != nil {
+			stackErrors = append(stackErrors, err.Error())
+		}
+	}
+
+	fmt.Printf("Validating %s\n", configFile)
+	printValidationGroup("❌ Errors", errors)
+	printValidationGroup("❌ GPU stack", stackErrors)
+	printValidationGroup("⚠️  Warnings", warnings)
+	fmt.Println()
+
+	if len(errors)+len(stackErrors) > 0 {
+		fmt.Printf("%s is invalid: %d error(s), %d warning(s)\n", configFile, len(errors)+len(stackErrors), len(warnings))
+		os.Exit(1)
+	}
+	fmt.Printf("✅ %s is valid (%d warning(s))\n", configFile, len(warnings))
+}
+
+// printValidationGroup prints one category of validation messages, if any
+func printValidationGroup(title string, messages []string) {
+	if len(messages) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Printf("%s (%d):\n", title, len(messages))
+	for _, msg := range messages {
+		fmt.Printf("   - %s\n", msg)
+	}
+}
+
+// printConfigWarnings prints non-fatal notices about contradictory configuration
+func printConfigWarnings(cfg config.Config) {
+	for _, warning := range config.Warnings(cfg) {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: %s\n", warning)
+	}
+}
+
+// runApply re-runs the post-Kubernetes task groups of the main playbook
+// against an already running cluster
+func runApply(configFile string) {
+	cfg := loadValidConfig(configFile)
+	storeConfigSnapshot(cfg)
+
+	if err := config.ApplyGPUStackVars(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error resolving GPU stack defaults: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Gate the reachability check so it never runs as part of a normal deploy
+	cfg["verify_cluster_reachable"] = true
+
+	exitCode, err := runtime.RunPlaybook(cfg, "cluster-bloom.yaml", dryRun, runtime.ApplyTags, runtime.OutputClean, Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(exitCode)
+}
+
+// runDNSCheck runs the cluster DNS smoke test against a running cluster
+func runDNSCheck(configFile string) {
+	cfg := loadValidConfig(configFile)
+
+	// Gate the reachability check and the DNS check so they only run on demand
+	cfg["verify_cluster_reachable"] = true
+	cfg["run_dns_check"] = true
+
+	exitCode, err := runtime.RunPlaybook(cfg, "cluster-bloom.yaml", dryRun, "verify_cluster,dns_check", runtime.OutputClean, Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(exitCode)
+}
+
+// runVerify checks the components bloom installed for the given config by
+// running the read-only verify_install tasks of the main playbook
+func runVerify(configFile string) {
+	cfg := loadValidConfig(configFile)
+
+	// Gate the checks so they never run as part of a normal deploy
+	cfg["run_verify_install"] = true
+
+	exitCode, err := runtime.RunPlaybook(cfg, "cluster-bloom.yaml", dryRun, "verify_install", runtime.OutputClean, Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(exitCode)
+}
+
+// runWait blocks until the cluster reports the expected Ready nodes and its
+// core components are available, exiting 1 on timeout
+func runWait() {
+	if expectedNodes < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --expected-nodes must be at least 1")
+		os.Exit(1)
+	}
+	if waitTimeout <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: --timeout must be greater than 0")
+		os.Exit(1)
+	}
+
+	err := runtime.WaitForCluster(runtime.WaitOptions{
+		Kubeconfig:    kubeconfigPath,
+		ExpectedNodes: expectedNodes,
+		Timeout:       waitTimeout,
+		SkipLonghorn:  skipLonghorn,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// storeConfigSnapshot passes the redacted user config to the playbook, which
+// stores it in the bloom ConfigMap for bloom config diff, and the fresh key of
+// its fingerprints, which goes into a Secret. Must run before any derived vars
+// are injected into cfg.
+func storeConfigSnapshot(cfg config.Config) {
+	delete(cfg, "bloom_config_snapshot")
+	delete(cfg, "bloom_config_redaction_key")
+	key := config.NewRedactionKey()
+	cfg["bloom_config_snapshot"] = map[string]any(config.Redact(cfg, key))
+	cfg["bloom_config_redaction_key"] = base64.StdEncoding.EncodeToString(key)
+}
+
+// legacyConfigMapKeys maps the summary keys of bloom ConfigMaps created before
+// the full config was stored to their bloom.yaml keys
+var legacyConfigMapKeys = map[string]string{
+	"DOMAIN":       "DOMAIN",
+	"cluster_size": "CLUSTER_SIZE",
+	"gpu_node":     "GPU_NODE",
+	"rke2_version": "RKE2_VERSION",
+}
+
+// runConfigDiff compares a local config against the one stored in the bloom
+// ConfigMap, exiting 1 when they differ and 2 on errors
+func runConfigDiff(configFile string) {
+	local, err := config.LoadConfig(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error loading config: %v\n", err)
+		os.Exit(2)
+	}
+
+	data, err := runtime.BloomConfigMap(kubeconfigPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error reading the bloom ConfigMap: %v\n", err)
+		os.Exit(2)
+	}
+
+	var deployed config.Config
+	var redactionKey []byte
+	if snapshot, ok := data[config.ConfigMapKey]; ok {
+		if err := yaml.Unmarshal([]byte(snapshot), &deployed); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error parsing %s from the bloom ConfigMap: %v\n", config.ConfigMapKey, err)
+			os.Exit(2)
+		}
+		if redactionKey, err = runtime.BloomSecretKey(kubeconfigPath, config.RedactionSecretName); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Could not read the %s Secret: %v\n", config.RedactionSecretName, err)
+			fmt.Fprintln(os.Stderr, "   Secret values are not compared. Run bloom apply to store it.")
+			redactionKey = nil
+		}
+	} else {
+		fmt.Fprintln(os.Stderr, "⚠️  The bloom ConfigMap has no full config snapshot (deployed by an older bloom);")
+		fmt.Fprintln(os.Stderr, "   comparing DOMAIN, CLUSTER_SIZE, GPU_NODE and RKE2_VERSION only. Run bloom apply to store it.")
+		deployed = config.Config{}
+		summary := config.Config{}
+		for cmKey, key := range legacyConfigMapKeys {
+			if value, ok := data[cmKey]; ok {
+				deployed[key] = value
+			}
+			if value, ok := local[key]; ok {
+				// The summary stores every value as a string
+				summary[key] = fmt.Sprint(value)
+			}
+		}
+		local = summary
+	}
+
+	changes := config.Diff(deployed, local, redactionKey)
+	if len(changes) == 0 {
+		fmt.Printf("✅ %s matches the deployed config\n", configFile)
+		return
+	}
+
+	fmt.Printf("%s differs from the deployed config in %d key(s):\n\n", configFile, len(changes))
+	for _, c := range changes {
+		switch c.Kind {
+		case config.ChangeAdded:
+			fmt.Printf("  + %s: %s\n", c.Key, c.New)
+		case config.ChangeRemoved:
+			fmt.Printf("  - %s: %s\n", c.Key, c.Old)
+		default:
+			fmt.Printf("  ~ %s: %s -> %s\n", c.Key, c.Old, c.New)
+		}
+	}
+	os.Exit(1)
+}
+
+// runSSHCleanup removes the bloom SSH key lines a killed run left in the
+// sudo user's authorized_keys
+func runSSHCleanup() {
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	username := runtime.ActualUser()
+	manager, err := ssh.NewEphemeralSSHManager(cwd, username, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	stale, backup, err := manager.StaleKeys()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(stale) == 0 {
+		fmt.Printf("✅ No bloom SSH keys in %s\n", manager.AuthorizedKeysPath)
+		return
+	}
+	if dryRun {
+		fmt.Printf("🔎 Dry run: would remove %d bloom key line(s) from %s:\n", len(stale), manager.AuthorizedKeysPath)
+		for _, line := range stale {
+			fmt.Printf("   - %s\n", line)
+		}
+		if backup != "" {
+			fmt.Printf("   by restoring %s\n", backup)
+		}
+		return
+	}
+
+	removed, restoredFrom, err := manager.RemoveStaleKeys()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("🧹 Removed %d bloom key line(s) from %s:\n", len(removed), manager.AuthorizedKeysPath)
+	for _, line := range removed {
+		fmt.Printf("   - %s\n", line)
+	}
+	if restoredFrom != "" {
+		fmt.Printf("✅ Restored %s\n", restoredFrom)
+	} else {
+		fmt.Println("✅ No backup without bloom keys found; removed the bloom lines only")
+	}
+}
+
+// runDoctor writes the diagnostics tarball for this node to the current
+// directory. The config is optional: a node that failed early may not have a
+// valid one, so it is only loaded, not validated.
+func runDoctor(configFile string) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts := runtime.DiagnosticsOptions{Dir: cwd}
+	if configFile != "" {
+		cfg, err := config.LoadConfig(configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not load config %s: %v\n", configFile, err)
+		} else {
+			opts.Secrets = config.SecretValues(cfg)
+			opts.GPUNode, _ = cfg["GPU_NODE"].(bool)
+			if data, err := yaml.Marshal(map[string]any(config.Redact(cfg, nil))); err == nil {
+				opts.Config = data
+			}
+		}
+	}
+	if !opts.GPUNode {
+		// Without a config, a node with ROCm installed is treated as a GPU node
+		if _, err := exec.LookPath("rocm-smi"); err == nil {
+			opts.GPUNode = true
+		}
+	}
+
+	fmt.Println("🩺 Collecting diagnostics...")
+	path, err := runtime.CollectDiagnostics(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Wrote %s\n", filepath.Base(path))
+	fmt.Println("   Attach it to the bug report. Tokens and passwords are redacted; review it before sharing.")
+}
+
+// runCertRenew regenerates the self-signed domain certificate by running the
+// renew_cert tasks of the main playbook against the given config
+func runCertRenew(configFile string) {
+	cfg := loadValidConfig(configFile)
+
+	// Gate the renew tasks so they never run as part of a normal deploy
+	cfg["renew_self_signed_cert"] = true
+
+	exitCode, err := runtime.RunPlaybook(cfg, "cluster-bloom.yaml", dryRun, "renew_cert", runtime.OutputClean, Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(exitCode)
+}
+
+// runDisksTeardown releases the bloom-managed CLUSTER_DISKS mounts on this node
+func runDisksTeardown(configFile string) {
+	cfg := loadValidConfig(configFile)
+
+	clusterDisks, _ := cfg["CLUSTER_DISKS"].(string)
+
+	entries, err := runtime.ListManagedClusterDisks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	entries = runtime.FilterManagedDisks(entries, clusterDisks)
+	if len(entries) == 0 {
+		fmt.Println("No bloom-managed cluster disks found in /etc/fstab. Nothing to do.")
+		return
+	}
+
+	runtime.PrintDiskTeardownPreview(entries, wipeDisks)
+	if dryRun {
+		fmt.Println("🔎 Dry run: no disks were unmounted or wiped.")
+		return
+	}
+	if !assumeYes {
+		if !confirmDisksTeardown() {
+			fmt.Println("❌ Disk teardown aborted by user.")
+			os.Exit(0)
+		}
+	}
+
+	if err := runtime.TeardownBloomDisks(entries, wipeDisks); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✅ Disk teardown complete")
+}
+
 func runPlaybookDirect(playbookPath string) {
 	mode := runtime.OutputClean
 	if verbose {
@@ -389,16 +1288,19 @@ func runPlaybookDirect(playbookPath string) {
 			fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
 			os.Exit(1)
 		}
-		var cfg map[string]any
-		if err := yaml.Unmarshal(data, &cfg); err != nil {
+		cfg, err := config.ParseConfig(configFile, data)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error parsing config: %v\n", err)
 			os.Exit(1)
 		}
 		allVars = append(allVars, runtime.ConfigToAnsibleVars(cfg)...)
+		requireEntropy, _ := cfg["REQUIRE_ENTROPY"].(bool)
+		runtime.SetRequireEntropy(requireEntropy)
 	}
 
 	allVars = append(allVars, extraVars...)
 
+	runtime.SetReuseSSHKey(reuseSSHKey)
 	exitCode, err := runtime.RunPlaybookDirect(playbookPath, dryRun, tags, allVars, mode, Version)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -576,6 +1478,26 @@ func confirmCleanupOperation() bool {
 	return true
 }
 
+// confirmDisksTeardown prompts the user to confirm the disks teardown command
+func confirmDisksTeardown() bool {
+	fmt.Println()
+	fmt.Print("Type \"yes\" to proceed with disk teardown: ")
+
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("\n❌ Error reading input: %v\n", err)
+		return false
+	}
+
+	input = strings.TrimSpace(input)
+	if input != "yes" {
+		fmt.Printf("\n❌ Disk teardown aborted. Received: \"%s\", expected: \"yes\"\n", input)
+		return false
+	}
+	return true
+}
+
 // checkRootPrivileges verifies that the current process is running with root privileges
 func checkRootPrivileges(commandName string) {
 	if os.Getuid() != 0 {
@@ -601,8 +1523,6 @@ func runClusterCleanup(cfg config.Config) {
 	// Initialize signal handling for graceful shutdown
 	runtime.InitSignalHandling()
 
-	var errors []error
-
 	// Extract CLUSTER_DISKS from config
 	clusterDisks := ""
 	if disks, exists := cfg["CLUSTER_DISKS"]; exists && disks != nil {
@@ -628,42 +1548,59 @@ func runClusterCleanup(cfg config.Config) {
 	}
 
 	fmt.Printf("   ⚙️  Config: CLUSTER_DISKS=%q, CLUSTER_PREMOUNTED_DISKS=%q, RANCHER_DISK=%q\n", clusterDisks, premountedDisks, rancherDisk)
-	// Step 1: Clean Longhorn Mounts (equivalent to CleanLonghornMountsStep)
-	if err := runtime.CleanupLonghornMounts(); err != nil {
-		errors = append(errors, fmt.Errorf("Longhorn cleanup: %w", err))
-	}
 
-	// Step 2: Uninstall RKE2 (equivalent to UninstallRKE2Step)
-	if err := runtime.UninstallRKE2(); err != nil {
-		errors = append(errors, fmt.Errorf("RKE2 uninstall: %w", err))
+	// Every step runs even when an earlier one fails, so a partially installed
+	// node is still torn down as far as possible
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		// Step 1: Clean Longhorn Mounts (equivalent to CleanLonghornMountsStep)
+		{"Longhorn cleanup", runtime.CleanupLonghornMounts},
+		// Step 2: Uninstall RKE2 (equivalent to UninstallRKE2Step)
+		{"RKE2 uninstall", runtime.UninstallRKE2},
+		// Step 3: Pre-clean bloom artifacts from directories in the future mount range,
+		// leaving user files intact. Done before fstab is rewritten so mounts are still valid.
+		{"Future mount pre-clean", func() error {
+			return runtime.PrecleanFutureMountPoints(clusterDisks, premountedDisks)
+		}},
+		// Step 4: Clean premounted disk contents BEFORE CleanupBloomDisks strips fstab.
+		// unmountPriorLonghornDisks (called inside CleanupBloomDisks) removes bloom fstab
+		// entries and unmounts the disks; if we run after that, mount falls back to device
+		// scan which may fail. Running here while fstab is intact guarantees the mount works.
+		{"Premounted disk cleanup", func() error {
+			return runtime.CleanupPremountedDisks(premountedDisks)
+		}},
+		// Step 4.5: Clean RANCHER_DISK configuration — unmount bind mount and clean data
+		// Always call - let function decide based on actual mount status
+		{"RANCHER_DISK cleanup", func() error {
+			return runtime.CleanupRancherDisk("")
+		}},
+		// Step 5: Clean Disks — strips fstab entries and wipes CLUSTER_DISKS
+		{"Disk cleanup", func() error {
+			return runtime.CleanupBloomDisks(clusterDisks)
+		}},
 	}
 
-	// Step 2.5: Process validation removed - config-independent cleanup proven sufficient
-
-	// Step 3: Pre-clean bloom artifacts from directories in the future mount range,
-	// leaving user files intact. Done before fstab is rewritten so mounts are still valid.
-	if err := runtime.PrecleanFutureMountPoints(clusterDisks, premountedDisks); err != nil {
-		errors = append(errors, fmt.Errorf("Future mount pre-clean: %w", err))
-	}
-
-	// Step 4: Clean premounted disk contents BEFORE CleanupBloomDisks strips fstab.
-	// unmountPriorLonghornDisks (called inside CleanupBloomDisks) removes bloom fstab
-	// entries and unmounts the disks; if we run after that, mount falls back to device
-	// scan which may fail. Running here while fstab is intact guarantees the mount works.
-	if err := runtime.CleanupPremountedDisks(premountedDisks); err != nil {
-		errors = append(errors, fmt.Errorf("Premounted disk cleanup: %w", err))
-	}
-
-	// Step 4.5: Clean RANCHER_DISK configuration — unmount bind mount and clean data
-	// Always call - let function decide based on actual mount status
-	if err := runtime.CleanupRancherDisk(""); err != nil {
-		errors = append(errors, fmt.Errorf("RANCHER_DISK cleanup: %w", err))
+	var errors []error
+	results := make([]error, len(steps))
+	for i, step := range steps {
+		if err := step.run(); err != nil {
+			results[i] = err
+			errors = append(errors, fmt.Errorf("%s: %w", step.name, err))
+		}
 	}
 
-	// Step 5: Clean Disks — strips fstab entries and wipes CLUSTER_DISKS
-	if err := runtime.CleanupBloomDisks(clusterDisks); err != nil {
-		errors = append(errors, fmt.Errorf("Disk cleanup: %w", err))
+	fmt.Println()
+	fmt.Println("📋 Cleanup summary:")
+	for i, step := range steps {
+		if results[i] != nil {
+			fmt.Printf("   [✗] %s: %v\n", step.name, results[i])
+		} else {
+			fmt.Printf("   [✓] %s\n", step.name)
+		}
 	}
+	fmt.Println()
 
 	// Report results
 	if len(errors) > 0 {
@@ -60,11 +60,27 @@ function setupEventListeners() {
         await handleGenerate();
     });
 
+    // Import button - replaces the form values with a pasted bloom.yaml
+    document.getElementById('import-btn').addEventListener('click', async () => {
+        await importYAML();
+    });
+
     // Download button - now saves to cwd
     document.getElementById('download-btn').addEventListener('click', async () => {
         await saveYAML();
     });
 
+    // Download button - fetches the generated file to this browser's machine
+    document.getElementById('download-local-btn').addEventListener('click', () => {
+        const query = apiToken ? `?token=${encodeURIComponent(apiToken)}` : '';
+        window.location.href = `/api/config/download${query}`;
+    });
+
+    // Install button - saves, then bloom installs from the terminal
+    document.getElementById('install-btn').addEventListener('click', async () => {
+        await installYAML();
+    });
+
     // Edit button
     document.getElementById('edit-btn').addEventListener('click', () => {
         document.getElementById('preview').classList.add('hidden');
@@ -83,7 +99,7 @@ async function handleGenerate() {
     }
 
     try {
-        const response = await fetch('/api/generate', {
+        const response = await apiFetch('/api/generate', {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
@@ -109,17 +125,58 @@ async function handleGenerate() {
     }
 }
 
+async function importYAML() {
+    const yaml = document.getElementById('import-yaml').value;
+    if (yaml.trim() === '') {
+        showError('Paste a bloom.yaml to import');
+        return;
+    }
+
+    try {
+        const response = await apiFetch('/api/config/import', {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/yaml',
+            },
+            body: yaml,
+        });
+
+        if (!response.ok) {
+            const body = (await response.text()).trim();
+            throw new Error(body || `HTTP error! status: ${response.status}`);
+        }
+
+        const result = await response.json();
+
+        // Keys missing from the import get their defaults, as in a fresh form
+        currentConfig = {};
+        schema.forEach(arg => {
+            currentConfig[arg.key] = getDefaultValue(arg);
+        });
+        Object.assign(currentConfig, result.config);
+        renderForm(schema, currentConfig);
+        updateFieldVisibility(schema, currentConfig);
+        clearValidationErrors();
+
+        document.getElementById('import-section').open = false;
+        let message = `Imported ${Object.keys(result.config).length} settings`;
+        if (result.unknown.length > 0) {
+            message += `; ignored unknown keys: ${result.unknown.join(', ')}`;
+        }
+        showSuccess(message);
+    } catch (error) {
+        showError('Failed to import YAML: ' + error.message);
+    }
+}
+
 async function saveYAML() {
     if (!currentConfig) {
         showError('No configuration available');
         return;
     }
 
+    // An empty filename is saved as bloom.yaml by the server
     const filename = document.getElementById('filename').value.trim();
-    if (!filename) {
-        showError('Please enter a filename');
-        return;
-    }
 
     // Get button reference and store original text
     const saveBtn = document.getElementById('download-btn');
@@ -129,7 +186,7 @@ async function saveYAML() {
     saveBtn.textContent = 'Saved';
 
     try {
-        const response = await fetch('/api/save', {
+        const response = await apiFetch('/api/save', {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
@@ -141,7 +198,15 @@ async function saveYAML() {
         });
 
         if (!response.ok) {
-            throw new Error(`HTTP error! status: ${response.status}`);
+            // Validation errors come back as JSON, invalid filenames as plain text
+            const body = (await response.text()).trim();
+            let reason = body;
+            try {
+                reason = JSON.parse(body).errors.join('\n');
+            } catch (e) {
+                // Not a validation response
+            }
+            throw new Error(reason || `HTTP error! status: ${response.status}`);
         }
 
         const result = await response.json();
@@ -159,6 +224,52 @@ async function saveYAML() {
     }
 }
 
+async function installYAML() {
+    if (!currentConfig) {
+        showError('No configuration available');
+        return;
+    }
+
+    const filename = document.getElementById('filename').value.trim();
+    const installBtn = document.getElementById('install-btn');
+    installBtn.disabled = true;
+
+    try {
+        const response = await apiFetch('/api/install', {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({
+                config: currentConfig,
+                filename: filename
+            }),
+        });
+
+        if (!response.ok) {
+            const body = (await response.text()).trim();
+            let reason = body;
+            try {
+                reason = JSON.parse(body).errors.join('\n');
+            } catch (e) {
+                // Not a validation response
+            }
+            throw new Error(reason || `HTTP error! status: ${response.status}`);
+        }
+
+        const result = await response.json();
+        // The server shuts down now, so leave the buttons disabled
+        document.getElementById('download-btn').disabled = true;
+        document.getElementById('download-local-btn').disabled = true;
+        document.getElementById('edit-btn').disabled = true;
+        installBtn.textContent = 'Installing';
+        showSuccess(`Saved to ${result.path}. Installation is running in the terminal where bloom was started; this page can be closed.`);
+    } catch (error) {
+        installBtn.disabled = false;
+        showError('Failed to start installation: ' + error.message);
+    }
+}
+
 function showError(message) {
     const errorDiv = document.getElementById('error');
     errorDiv.style.whiteSpace = 'pre-line';
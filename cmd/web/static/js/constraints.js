@@ -9,7 +9,7 @@ async function loadConstraints() {
     }
 
     try {
-        const response = await fetch('/api/schema');
+        const response = await apiFetch('/api/schema');
         if (!response.ok) {
             throw new Error(`HTTP error! status: ${response.status}`);
         }
@@ -37,7 +37,7 @@ function createFormField(argument, config) {
         input.type = 'checkbox';
         input.id = argument.key;
         input.name = argument.key;
-        input.checked = getDefaultValue(argument);
+        input.checked = initialValue(argument, config) === true;
 
         const checkLabel = document.createElement('span');
         checkLabel.textContent = 'Enabled';
@@ -62,11 +62,12 @@ function createFormField(argument, config) {
         input.appendChild(emptyOption);
 
         // Add options
+        const selected = initialValue(argument, config);
         argument.options.forEach(opt => {
             const option = document.createElement('option');
             option.value = opt;
             option.textContent = opt;
-            if (opt === argument.default) {
+            if (opt === selected) {
                 option.selected = true;
             }
             input.appendChild(option);
@@ -88,7 +89,7 @@ function createFormField(argument, config) {
         group.appendChild(input);
     } else if (argument.type === 'array') {
         // Handle array fields (like ADDITIONAL_OIDC_PROVIDERS)
-        const arrayContainer = createArrayField(argument);
+        const arrayContainer = createArrayField(argument, initialValue(argument, config));
         group.appendChild(arrayContainer);
         
         // Arrays don't have a traditional input element
@@ -97,7 +98,7 @@ function createFormField(argument, config) {
         input = document.createElement('input');
         input.id = argument.key;
         input.name = argument.key;
-        input.value = getDefaultValue(argument);
+        input.value = initialValue(argument, config);
         input.placeholder = argument.default || '';
 
         // Apply HTML5 validation attributes from schema
@@ -317,7 +318,7 @@ function getFormData(schema) {
 }
 
 // Create array field component for dynamic lists (like OIDC providers)
-function createArrayField(argument) {
+function createArrayField(argument, items) {
     const container = document.createElement('div');
     container.className = 'array-field-container';
     container.dataset.key = argument.key;
@@ -334,9 +335,9 @@ function createArrayField(argument) {
     addButton.textContent = getAddButtonText(argument.key);
     container.appendChild(addButton);
     
-    // Initialize with default items if any
-    const defaultItems = argument.default || [];
-    defaultItems.forEach((item, index) => {
+    // Initialize with the given or default items if any
+    const initialItems = items || argument.default || [];
+    initialItems.forEach((item, index) => {
         addArrayItem(argument, itemsContainer, item, index);
     });
     
@@ -477,6 +478,38 @@ function createOIDCProviderFields(container, providerData, index) {
     audiencesGroup.appendChild(audiencesDesc);
     
     container.appendChild(audiencesGroup);
+
+    // Optional per-issuer claim mappings (empty falls back to the defaults)
+    const optionalFields = [
+        { field: 'username_claim', label: 'Username Claim', placeholder: 'preferred_username', description: 'JWT claim used as the username (default: preferred_username)' },
+        { field: 'groups_claim', label: 'Groups Claim', placeholder: 'groups', description: 'JWT claim used for group membership (default: groups)' },
+        { field: 'prefix', label: 'Claim Prefix', placeholder: 'oidc:', description: 'Prefix for usernames and groups from this issuer (default: OIDC_USERNAME_PREFIX / OIDC_GROUPS_PREFIX)' },
+        { field: 'expected_fingerprint', label: 'Expected Certificate Fingerprint', placeholder: 'AB:CD:...', description: 'SHA-256 fingerprint of the issuer TLS certificate; the deployment fails if the issuer presents a different one (default: not checked)' }
+    ];
+    optionalFields.forEach(({ field, label, placeholder, description }) => {
+        const group = document.createElement('div');
+        group.className = 'form-group';
+
+        const fieldLabel = document.createElement('label');
+        fieldLabel.textContent = label;
+        fieldLabel.setAttribute('for', `oidc_${field}_${index}`);
+        group.appendChild(fieldLabel);
+
+        const input = document.createElement('input');
+        input.type = 'text';
+        input.id = `oidc_${field}_${index}`;
+        input.name = `oidc_${field}_${index}`;
+        input.placeholder = placeholder;
+        input.value = providerData && providerData[field] ? providerData[field] : '';
+        group.appendChild(input);
+
+        const desc = document.createElement('div');
+        desc.className = 'description';
+        desc.textContent = description;
+        group.appendChild(desc);
+
+        container.appendChild(group);
+    });
     
     // Add validation
     urlInput.addEventListener('blur', () => {
@@ -646,6 +679,14 @@ function collectArrayData(key) {
                 } else {
                     provider.audiences = ['k8s']; // Default audience
                 }
+
+                // Only include optional fields that were set, so defaults apply otherwise
+                ['username_claim', 'groups_claim', 'prefix', 'expected_fingerprint'].forEach(field => {
+                    const input = item.querySelector(`#oidc_${field}_${index}`);
+                    if (input && input.value.trim()) {
+                        provider[field] = input.value.trim();
+                    }
+                });
                 
                 items.push(provider);
             }
@@ -2,13 +2,28 @@
 
 let cachedSchema = null;
 
+// Token from the page URL (?token=...), forwarded to the API when the web UI
+// was started with WEBUI_AUTH_TOKEN
+const apiToken = new URLSearchParams(window.location.search).get('token');
+
+async function apiFetch(url, options = {}) {
+    if (apiToken) {
+        options.headers = { ...(options.headers || {}), 'Authorization': `Bearer ${apiToken}` };
+    }
+    const response = await fetch(url, options);
+    if (response.status === 401) {
+        throw new Error('Unauthorized: open the web UI with ?token=<WEBUI_AUTH_TOKEN> in the URL');
+    }
+    return response;
+}
+
 async function fetchSchema() {
     if (cachedSchema) {
         return cachedSchema;
     }
 
     try {
-        const response = await fetch('/api/schema');
+        const response = await apiFetch('/api/schema');
         if (!response.ok) {
             throw new Error(`HTTP error! status: ${response.status}`);
         }
@@ -52,6 +67,15 @@ function isFieldVisible(argument, config) {
     return deps.every(dep => evaluateDependency(dep.trim(), config));
 }
 
+// Value a field starts with: the config's value when it has one (e.g. after an
+// import), the schema default otherwise
+function initialValue(argument, config) {
+    if (config && Object.prototype.hasOwnProperty.call(config, argument.key)) {
+        return config[argument.key];
+    }
+    return getDefaultValue(argument);
+}
+
 function getDefaultValue(argument) {
     if (argument.type === 'bool') {
         return argument.default === true;
@@ -59,5 +83,9 @@ function getDefaultValue(argument) {
     if (argument.type === 'array') {
         return argument.default || [];
     }
+    if (argument.type === 'map') {
+        // Shown as comma-separated key=value pairs, which the validator accepts
+        return Object.entries(argument.default || {}).map(([k, v]) => `${k}=${v}`).join(', ');
+    }
     return argument.default || '';
 }
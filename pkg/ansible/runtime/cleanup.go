@@ -9,6 +9,8 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -92,39 +94,7 @@ func CleanupLonghornMounts() error {
 	exec.Command("iscsiadm", "-m", "node", "--op=delete").Run()
 
 	// Step 3: Graceful TERM then KILL of Longhorn processes in dependency order
-	// Use exact binary name matching to avoid killing unrelated processes.
-	// Longhorn binaries have these exact names in /proc/*/comm.
-	longhornProcs := []string{"longhorn-engine", "longhorn-instance-manager", "longhorn-manager"}
-	anyRunning := false
-	for _, proc := range longhornProcs {
-		if exec.Command("pgrep", "-x", proc).Run() == nil {
-			anyRunning = true
-			break
-		}
-	}
-	if anyRunning {
-		fmt.Println("   🛑 Stopping Longhorn processes (TERM)...")
-		for _, proc := range longhornProcs {
-			exec.Command("pkill", "-TERM", "-x", proc).Run()
-		}
-		time.Sleep(5 * time.Second)
-		// Only KILL if some processes survived TERM
-		stillRunning := false
-		for _, proc := range longhornProcs {
-			if exec.Command("pgrep", "-x", proc).Run() == nil {
-				stillRunning = true
-				break
-			}
-		}
-		if stillRunning {
-			fmt.Println("      ⚡ Force killing remaining Longhorn processes (KILL)...")
-			for _, proc := range longhornProcs {
-				exec.Command("pkill", "-KILL", "-x", proc).Run()
-			}
-		}
-	} else {
-		fmt.Println("   ℹ️  No Longhorn processes running — skipping")
-	}
+	stopLonghornProcesses()
 
 	// Step 4: Force umount everything Longhorn-related
 	fmt.Println("   ⏏️  Unmounting Longhorn volumes...")
@@ -151,6 +121,44 @@ func CleanupLonghornMounts() error {
 	return nil
 }
 
+// longhornProcs are the exact binary names of the Longhorn processes, as they
+// appear in /proc/*/comm, in the order they are stopped
+var longhornProcs = []string{"longhorn-engine", "longhorn-instance-manager", "longhorn-manager"}
+
+// longhornTermGrace is how long Longhorn processes get to exit after TERM
+var longhornTermGrace = 5 * time.Second
+
+// stopLonghornProcesses sends TERM to the Longhorn processes and KILL to any
+// that survive the grace period. Exact name matching (-x) avoids killing
+// unrelated processes.
+func stopLonghornProcesses() {
+	running := func() bool {
+		for _, proc := range longhornProcs {
+			if _, err := runner.CombinedOutput("pgrep", "-x", proc); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+	if !running() {
+		fmt.Println("   ℹ️  No Longhorn processes running — skipping")
+		return
+	}
+
+	fmt.Println("   🛑 Stopping Longhorn processes (TERM)...")
+	for _, proc := range longhornProcs {
+		runner.CombinedOutput("pkill", "-TERM", "-x", proc)
+	}
+	time.Sleep(longhornTermGrace)
+	// Only KILL if some processes survived TERM
+	if running() {
+		fmt.Println("      ⚡ Force killing remaining Longhorn processes (KILL)...")
+		for _, proc := range longhornProcs {
+			runner.CombinedOutput("pkill", "-KILL", "-x", proc)
+		}
+	}
+}
+
 // isKubeAPIReachable checks that the RKE2 API server is both reachable and
 // responsive at the HTTP level. A plain TCP dial is not sufficient — a degraded
 // or starting-up API server can accept the connection then stall on the request.
@@ -187,15 +195,34 @@ func isNodeInCluster(kubeconfig, nodeName string) bool {
 	return strings.TrimSpace(string(out)) != ""
 }
 
+// rke2UninstallScript and rke2DataDirs are what UninstallRKE2 runs and removes
+var (
+	rke2UninstallScript = "/usr/local/bin/rke2-uninstall.sh"
+	rke2DataDirs        = []string{"/etc/rancher/rke2", DefaultRKE2DataDir, "/var/lib/kubelet"}
+)
+
+// rke2RemovalDirs returns the directories UninstallRKE2 removes, including the
+// RKE2 entries of a data-dir relocated with RKE2_DATA_DIR
+func rke2RemovalDirs() []string {
+	if dataDir := RKE2DataDir(); dataDir != DefaultRKE2DataDir {
+		return append(append([]string{}, rke2DataDirs...), rke2DataDirRemovals(dataDir)...)
+	}
+	return rke2DataDirs
+}
+
 // UninstallRKE2 executes the RKE2 uninstall script if it exists
 func UninstallRKE2() error {
 	fmt.Println("🔧 Uninstalling RKE2...")
 
+	// Read before the script removes config.yaml, so a relocated data-dir is
+	// cleaned as well
+	dataDir := RKE2DataDir()
+	dirs := rke2RemovalDirs()
+
 	// Run uninstall script if it exists
-	if _, err := os.Stat("/usr/local/bin/rke2-uninstall.sh"); err == nil {
+	if _, err := os.Stat(rke2UninstallScript); err == nil {
 		fmt.Println("   ⏳ Executing RKE2 uninstall script (may take a couple minutes)...")
-		cmd := exec.Command("/usr/local/bin/rke2-uninstall.sh")
-		output, err := cmd.CombinedOutput()
+		output, err := runner.CombinedOutput(rke2UninstallScript)
 
 		// Log output regardless of error (matching Bloom v1 behavior)
 		if len(output) > 0 {
@@ -215,28 +242,26 @@ func UninstallRKE2() error {
 	// Always force-remove RKE2 directories to ensure clean state
 	// This handles cases where the uninstall script doesn't exist, fails, or leaves remnants
 	fmt.Println("   🗑️  Removing RKE2 directories and data...")
-	directories := []string{
-		"/etc/rancher/rke2",
-		"/var/lib/rancher/rke2",
-		"/var/lib/kubelet",
-	}
-
-	for _, dir := range directories {
+	for _, dir := range dirs {
 		if _, err := os.Stat(dir); err == nil {
-			cmd := exec.Command("rm", "-rf", dir)
-			if err := cmd.Run(); err != nil {
+			if _, err := runner.CombinedOutput("rm", "-rf", dir); err != nil {
 				fmt.Printf("      ⚠️  Warning: Failed to remove %s: %v\n", dir, err)
 			} else {
 				fmt.Printf("      ✓ Removed %s\n", dir)
 			}
 		}
 	}
+	// rmdir leaves a relocated data-dir in place if anything else lives there
+	if dataDir != DefaultRKE2DataDir {
+		if _, err := runner.CombinedOutput("rmdir", dataDir); err == nil {
+			fmt.Printf("      ✓ Removed %s\n", dataDir)
+		}
+	}
 
 	fmt.Println("   ✅ RKE2 uninstall completed")
 	return nil
 }
 
-
 // CleanupBloomDisks removes bloom-managed disks and cleans up disk state
 func CleanupBloomDisks(clusterDisks string) error {
 	fmt.Println("💽 Cleaning bloom-managed disks...")
@@ -257,6 +282,11 @@ func CleanupBloomDisks(clusterDisks string) error {
 		fmt.Printf("   ⚠️  Warning: Failed to unmount CLUSTER_DISKS: %v\n", err)
 	}
 
+	// Close ENCRYPT_DISKS LUKS containers so wipefs can reach the raw devices
+	if err := closeBloomLUKSMappings(); err != nil {
+		fmt.Printf("   ⚠️  Warning: Failed to close LUKS mappings: %v\n", err)
+	}
+
 	// Parse mount output to find and unmount CSI driver mounts
 	fmt.Println("   🔍 Checking for CSI driver mounts...")
 	cmd := exec.Command("mount")
@@ -343,7 +373,7 @@ func CleanupBloomDisks(clusterDisks string) error {
 	scanner = bufio.NewScanner(strings.NewReader(string(output)))
 	for scanner.Scan() {
 		fields := strings.Fields(scanner.Text())
-		if len(fields) == 3 && strings.HasPrefix(fields[0], "sd") && fields[1] == "disk" && fields[2] == "" {
+		if isUnusedSCSIDisk(fields) {
 			deleteCmd := exec.Command("sudo", "tee", "/sys/block/"+fields[0]+"/device/delete")
 			deleteCmd.Stdin = strings.NewReader("1\n")
 			if err := deleteCmd.Run(); err != nil {
@@ -365,6 +395,57 @@ func CleanupBloomDisks(clusterDisks string) error {
 	return nil
 }
 
+// luksMapperPrefix names the /dev/mapper devices storage.yaml opens for
+// ENCRYPT_DISKS, bloom-diskN for /mnt/diskN
+const luksMapperPrefix = "bloom-disk"
+
+// isBloomCrypttabEntry reports whether a crypttab line is one of the
+// ENCRYPT_DISKS entries closeBloomLUKSMappings removes
+func isBloomCrypttabEntry(line string) bool {
+	fields := strings.Fields(line)
+	return len(fields) > 0 && strings.HasPrefix(fields[0], luksMapperPrefix)
+}
+
+// closeBloomLUKSMappings unmounts and closes the LUKS containers opened for
+// ENCRYPT_DISKS and removes their /etc/crypttab entries
+func closeBloomLUKSMappings() error {
+	mappers, _ := filepath.Glob("/dev/mapper/" + luksMapperPrefix + "*")
+	for _, mapper := range mappers {
+		name := filepath.Base(mapper)
+		exec.Command("umount", "-lf", mapper).Run()
+		if out, err := exec.Command("cryptsetup", "close", name).CombinedOutput(); err != nil {
+			fmt.Printf("      ⚠️  Warning: Failed to close %s: %v %s\n", name, err, strings.TrimSpace(string(out)))
+		} else {
+			fmt.Printf("      ✓ Closed LUKS mapping %s\n", name)
+		}
+	}
+
+	data, err := os.ReadFile("/etc/crypttab")
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read crypttab: %w", err)
+	}
+	var kept []string
+	removed := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		if isBloomCrypttabEntry(line) {
+			removed++
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if removed == 0 {
+		return nil
+	}
+	if err := os.WriteFile("/etc/crypttab", []byte(strings.Join(kept, "\n")), 0644); err != nil {
+		return fmt.Errorf("failed to update crypttab: %w", err)
+	}
+	fmt.Printf("      ✓ Removed %d bloom entries from /etc/crypttab\n", removed)
+	return nil
+}
+
 // unmountClusterDisks directly unmounts all devices found in CLUSTER_DISKS
 func unmountClusterDisks(clusterDisks string) error {
 	if clusterDisks == "" {
@@ -401,6 +482,13 @@ func unmountClusterDisks(clusterDisks string) error {
 func GenerateCleanupTasks(clusterDisks string, premountedDisks string, rancherDisk string) []map[string]any {
 	var cleanupTasks []map[string]any
 
+	// A relocated data-dir loses only its RKE2 entries, and itself once empty
+	dataDir := RKE2DataDir()
+	rke2Cleanup := "rm -rf " + strings.Join(rke2DataDirRemovals(dataDir), " ") + " /etc/rancher/rke2 /var/lib/kubelet /var/log/pods /var/log/containers; "
+	if dataDir != DefaultRKE2DataDir {
+		rke2Cleanup += "rmdir " + dataDir + " 2>/dev/null; "
+	}
+
 	// Main cleanup block task
 	cleanupBlock := map[string]any{
 		"name": "⚠️ DESTRUCTIVE CLEANUP: Remove existing Bloom cluster installation",
@@ -437,13 +525,13 @@ func GenerateCleanupTasks(clusterDisks string, premountedDisks string, rancherDi
 			},
 			{
 				"name":        "Cordon node to prevent new scheduling",
-				"shell":       "/var/lib/rancher/rke2/bin/kubectl --kubeconfig /etc/rancher/rke2/rke2.yaml cordon {{ cleanup_hostname.stdout }} 2>/dev/null || true",
+				"shell":       rke2Kubectl() + " --kubeconfig /etc/rancher/rke2/rke2.yaml cordon {{ cleanup_hostname.stdout }} 2>/dev/null || true",
 				"when":        "cleanup_kubeconfig.stat.exists",
 				"failed_when": false,
 			},
 			{
 				"name":        "Drain node (best-effort, allows Longhorn to detach volumes)",
-				"shell":       "/var/lib/rancher/rke2/bin/kubectl --kubeconfig /etc/rancher/rke2/rke2.yaml drain {{ cleanup_hostname.stdout }} --delete-emptydir-data --ignore-daemonsets --force --disable-eviction --grace-period=10 --timeout=30s 2>/dev/null || true",
+				"shell":       rke2Kubectl() + " --kubeconfig /etc/rancher/rke2/rke2.yaml drain {{ cleanup_hostname.stdout }} --delete-emptydir-data --ignore-daemonsets --force --disable-eviction --grace-period=10 --timeout=30s 2>/dev/null || true",
 				"when":        "cleanup_kubeconfig.stat.exists",
 				"failed_when": false,
 			},
@@ -508,7 +596,7 @@ func GenerateCleanupTasks(clusterDisks string, premountedDisks string, rancherDi
 			},
 			{
 				"name":        "Clean RKE2 directories and files",
-				"shell":       "rm -rf /var/lib/rancher/rke2 /etc/rancher/rke2 /var/lib/kubelet /var/log/pods /var/log/containers; rm -f /usr/local/bin/rke2* /usr/local/bin/kubectl /usr/local/bin/crictl /usr/local/bin/ctr; echo 'RKE2 cleanup completed'",
+				"shell":       rke2Cleanup + "rm -f /usr/local/bin/rke2* /usr/local/bin/kubectl /usr/local/bin/crictl /usr/local/bin/ctr; echo 'RKE2 cleanup completed'",
 				"register":    "rke2_cleanup",
 				"failed_when": false,
 			},
@@ -535,6 +623,11 @@ func GenerateCleanupTasks(clusterDisks string, premountedDisks string, rancherDi
 					"loop":        "{{ cluster_disks_cleanup_list }}",
 					"failed_when": false,
 				},
+				{
+					"name":        "Close ENCRYPT_DISKS LUKS containers",
+					"shell":       fmt.Sprintf(`for m in /dev/mapper/%s*; do [ -e "$m" ] || continue; umount -lf "$m" 2>/dev/null; cryptsetup close "$(basename "$m")"; done; sed -i '/^%s/d' /etc/crypttab 2>/dev/null || true`, luksMapperPrefix, luksMapperPrefix),
+					"failed_when": false,
+				},
 				{
 					"name":        "Remove bloom-managed fstab entries (preserve premounted entries)",
 					"shell":       "sed -i '/# managed by cluster-bloom/{/# premounted by cluster-bloom/!d}' /etc/fstab",
@@ -860,18 +953,62 @@ user = append(user, e.Name())
 return
 }
 
-// countClusterDisksStr counts non-empty entries in a comma-separated CLUSTER_DISKS string.
+// countClusterDisksStr counts the distinct disks in a comma-separated CLUSTER_DISKS string.
 func countClusterDisksStr(clusterDisks string) int {
-if clusterDisks == "" {
-return 0
+	return len(orderClusterDisks(clusterDisks))
+}
+
+// orderClusterDisks returns the distinct CLUSTER_DISKS devices in the order
+// the next deployment mounts them at /mnt/diskN. prepare_node/disk_facts.yaml
+// keeps disks that are still mounted at a /mnt/diskN in place; after cleanup
+// none are, so the order is by device path, with digit runs compared as
+// numbers like sort -V (nvme2n1 before nvme10n1).
+func orderClusterDisks(clusterDisks string) []string {
+	var disks []string
+	for disk := range strings.SplitSeq(clusterDisks, ",") {
+		if disk = strings.TrimSpace(disk); disk != "" && !slices.Contains(disks, disk) {
+			disks = append(disks, disk)
+		}
+	}
+	slices.SortFunc(disks, compareVersion)
+	return disks
 }
-count := 0
-for _, d := range strings.Split(clusterDisks, ",") {
-if strings.TrimSpace(d) != "" {
-count++
+
+// compareVersion orders strings like sort -V: runs of digits compare by
+// numeric value and everything else byte by byte.
+func compareVersion(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			i, j := digitRun(a), digitRun(b)
+			na, nb := strings.TrimLeft(a[:i], "0"), strings.TrimLeft(b[:j], "0")
+			if c := len(na) - len(nb); c != 0 {
+				return c
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			a, b = a[i:], b[j:]
+			continue
+		}
+		if a[0] != b[0] {
+			return int(a[0]) - int(b[0])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) - len(b)
 }
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
 }
-return count
+
+// digitRun returns the length of the run of digits s starts with
+func digitRun(s string) int {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
 }
 
 // calculateFutureDiskStart returns the lowest start index S such that the sequential
@@ -1235,12 +1372,13 @@ func PrintDiskWipePreview(clusterDisks, premountedDisks, rancherDisk string) {
 	
 	managed := parseManagedFstabMounts()
 
-	var future []string
-	n := countClusterDisksStr(clusterDisks)
-	if n > 0 {
+	var future, futureOrder []string
+	disks := orderClusterDisks(clusterDisks)
+	if n := len(disks); n > 0 {
 		start := calculateFutureDiskStart(clusterDisks, premountedDisks, n)
-		for i := 0; i < n; i++ {
+		for i, disk := range disks {
 			future = append(future, fmt.Sprintf("/mnt/disk%d", start+i))
+			futureOrder = append(futureOrder, fmt.Sprintf("%s → %s", disk, future[i]))
 		}
 	}
 
@@ -1354,6 +1492,7 @@ if len(future) > 0 {
 first := future[0]
 last := future[len(future)-1]
 fmt.Printf("\n  Future mount range (%s – %s): bloom artifacts pre-cleaned, user files preserved\n", first, last)
+fmt.Printf("    Next deployment mounts %s\n", strings.Join(futureOrder, ", "))
 for _, mp := range future {
 bloom, user := inspectDirContents(mp)
 if _, err := os.Stat(mp); os.IsNotExist(err) {
@@ -1409,6 +1548,20 @@ return nil
 }
 
 
+// isBloomFstabRemoval reports whether unmountPriorLonghornDisks removes an
+// fstab line. Only entries tagged "# managed by cluster-bloom" (CLUSTER_DISKS,
+// RANCHER_DISK) are removed; entries tagged "# premounted by cluster-bloom"
+// (CLUSTER_PREMOUNTED_DISKS) survive cleanup with their filesystem intact.
+func isBloomFstabRemoval(line string) bool {
+	return strings.Contains(line, "# managed by cluster-bloom") && !strings.Contains(line, "# premounted by cluster-bloom")
+}
+
+// isUnusedSCSIDisk reports whether an lsblk -nd -o NAME,TYPE,MOUNTPOINT line
+// is an unmounted sd* disk, which CleanupBloomDisks deletes from the kernel
+func isUnusedSCSIDisk(fields []string) bool {
+	return len(fields) == 3 && strings.HasPrefix(fields[0], "sd") && fields[1] == "disk" && fields[2] == ""
+}
+
 // unmountPriorLonghornDisks helper function to handle fstab cleanup
 func unmountPriorLonghornDisks() error {
 	// Read fstab to find bloom-managed entries
@@ -1431,10 +1584,7 @@ func unmountPriorLonghornDisks() error {
 	var cleanLines []string
 
 	for _, line := range lines {
-		// Only remove entries tagged "# managed by cluster-bloom" (CLUSTER_DISKS).
-		// Entries tagged "# premounted by cluster-bloom" (CLUSTER_PREMOUNTED_DISKS) are
-		// intentionally skipped — premounted disks survive cleanup with filesystem intact.
-		if strings.Contains(line, "# managed by cluster-bloom") && !strings.Contains(line, "# premounted by cluster-bloom") {
+		if isBloomFstabRemoval(line) {
 			fields := strings.Fields(line)
 			if len(fields) >= 2 {
 				mountPoint := fields[1]
@@ -0,0 +1,183 @@
+//go:build linux
+
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// CleanupPlan is what bloom cleanup would do on this node, listed by
+// --dry-run without touching anything
+type CleanupPlan struct {
+	Unmounts         []string // Mount points that are unmounted
+	FstabRemovals    []string // /etc/fstab lines that are removed
+	CrypttabRemovals []string // /etc/crypttab lines that are removed
+	LUKSMappings     []string // ENCRYPT_DISKS mappings that are closed
+	RKE2Uninstall    string   // Uninstall script that is run, empty if absent
+	RemovedDirs      []string // Directories that are deleted
+	WipedDevices     []string // Devices wiped and reformatted as ext4
+	DeletedDevices   []string // Unused SCSI disks deleted from the kernel
+}
+
+// cleanupState is the node state a CleanupPlan is computed from
+type cleanupState struct {
+	fstab    string // /etc/fstab
+	mounts   string // /proc/mounts
+	crypttab string // /etc/crypttab
+	lsblk    string // lsblk -nd -o NAME,TYPE,MOUNTPOINT
+	mappers  []string
+}
+
+// PlanCleanup lists what runClusterCleanup would unmount, remove and wipe
+// for clusterDisks, using the same selection as the cleanup functions
+func PlanCleanup(clusterDisks string) CleanupPlan {
+	var state cleanupState
+	if data, err := os.ReadFile("/etc/fstab"); err == nil {
+		state.fstab = string(data)
+	}
+	if data, err := os.ReadFile("/proc/mounts"); err == nil {
+		state.mounts = string(data)
+	}
+	if data, err := os.ReadFile("/etc/crypttab"); err == nil {
+		state.crypttab = string(data)
+	}
+	if out, err := exec.Command("lsblk", "-nd", "-o", "NAME,TYPE,MOUNTPOINT").Output(); err == nil {
+		state.lsblk = string(out)
+	}
+	state.mappers, _ = filepath.Glob("/dev/mapper/" + luksMapperPrefix + "*")
+
+	plan := planCleanup(clusterDisks, state)
+	if _, err := os.Stat(rke2UninstallScript); err == nil {
+		plan.RKE2Uninstall = rke2UninstallScript
+	}
+	var dirs []string
+	for _, dir := range rke2RemovalDirs() {
+		if _, err := os.Stat(dir); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	plan.RemovedDirs = append(dirs, plan.RemovedDirs...)
+	return plan
+}
+
+// planCleanup computes the disk part of a CleanupPlan from state
+func planCleanup(clusterDisks string, state cleanupState) CleanupPlan {
+	var plan CleanupPlan
+	unmounting := map[string]bool{}
+	unmount := func(mountPoint string) {
+		if !unmounting[mountPoint] {
+			unmounting[mountPoint] = true
+			plan.Unmounts = append(plan.Unmounts, mountPoint)
+		}
+	}
+
+	var devices []string
+	for device := range strings.SplitSeq(clusterDisks, ",") {
+		if device = strings.TrimSpace(device); device != "" {
+			devices = append(devices, device)
+		}
+	}
+
+	// CleanupLonghornMounts, CleanupBloomDisks and CleanupRancherDisk, in order
+	mounts := map[string]string{}
+	rancherDevice := ""
+	for _, line := range strings.Split(state.mounts, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		source, mountPoint := fields[0], fields[1]
+		mounts[mountPoint] = source
+		switch {
+		case isLonghornMount(source, mountPoint):
+			unmount(mountPoint)
+		case slices.Contains(devices, source), strings.HasPrefix(source, "/dev/mapper/"+luksMapperPrefix):
+			unmount(mountPoint)
+		case mountPoint == "/var/lib/rancher":
+			rancherDevice = source
+		}
+	}
+	for _, line := range strings.Split(state.fstab, "\n") {
+		fields := strings.Fields(line)
+		if isBloomFstabRemoval(line) {
+			plan.FstabRemovals = append(plan.FstabRemovals, line)
+			if len(fields) >= 2 && mounts[fields[1]] != "" {
+				unmount(fields[1])
+			}
+		} else if rancherDevice != "" && len(fields) >= 2 && fields[1] == "/var/lib/rancher" {
+			plan.FstabRemovals = append(plan.FstabRemovals, line)
+		}
+	}
+	if rancherDevice != "" {
+		unmount("/var/lib/rancher")
+		plan.RemovedDirs = append(plan.RemovedDirs, "/var/lib/rancher (recreated empty)")
+	}
+
+	for _, mapper := range state.mappers {
+		plan.LUKSMappings = append(plan.LUKSMappings, filepath.Base(mapper))
+	}
+	for _, line := range strings.Split(state.crypttab, "\n") {
+		if isBloomCrypttabEntry(line) {
+			plan.CrypttabRemovals = append(plan.CrypttabRemovals, line)
+		}
+	}
+
+	plan.WipedDevices = append(plan.WipedDevices, devices...)
+	if strings.HasPrefix(rancherDevice, "/dev/") && !slices.Contains(devices, rancherDevice) {
+		plan.WipedDevices = append(plan.WipedDevices, rancherDevice)
+	}
+
+	for _, line := range strings.Split(state.lsblk, "\n") {
+		if fields := strings.Fields(line); isUnusedSCSIDisk(fields) {
+			plan.DeletedDevices = append(plan.DeletedDevices, "/dev/"+fields[0])
+		}
+	}
+	return plan
+}
+
+// isLonghornMount reports whether CleanupLonghornMounts unmounts a mount:
+// Longhorn volumes and the kubelet CSI and volume-subpath mounts
+func isLonghornMount(source, mountPoint string) bool {
+	if strings.Contains(source, "longhorn") || strings.Contains(mountPoint, "longhorn") {
+		return true
+	}
+	return strings.HasPrefix(mountPoint, "/var/lib/kubelet/pods/") &&
+		(strings.Contains(mountPoint, "/volumes/kubernetes.io~csi/") || strings.Contains(mountPoint, "/volume-subpaths/"))
+}
+
+// Print writes the plan in the layout of the disk wipe preview
+func (p CleanupPlan) Print() {
+	sep := strings.Repeat("─", 62)
+	fmt.Printf("\n%s\n", sep)
+	fmt.Println("  🔎  CLEANUP DRY RUN — nothing below is executed")
+	fmt.Printf("%s\n", sep)
+
+	section := func(title string, items []string) {
+		if len(items) == 0 {
+			fmt.Printf("  %s: none\n", title)
+			return
+		}
+		fmt.Printf("  %s:\n", title)
+		for _, item := range items {
+			fmt.Printf("    • %s\n", item)
+		}
+	}
+	section("Mounts to unmount", p.Unmounts)
+	section("/etc/fstab lines to remove", p.FstabRemovals)
+	section("LUKS mappings to close", p.LUKSMappings)
+	section("/etc/crypttab lines to remove", p.CrypttabRemovals)
+	if p.RKE2Uninstall != "" {
+		fmt.Printf("  RKE2 uninstall script to run: %s\n", p.RKE2Uninstall)
+	} else {
+		fmt.Println("  RKE2 uninstall script to run: none (not installed)")
+	}
+	section("Directories to delete", p.RemovedDirs)
+	section("Devices to wipe and reformat as ext4", p.WipedDevices)
+	section("Unused SCSI disks to delete from the kernel", p.DeletedDevices)
+	fmt.Printf("%s\n\n", sep)
+}
@@ -0,0 +1,210 @@
+//go:build linux
+
+package runtime
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordingRunner records every command instead of running it and replays
+// canned results keyed by the command line. Unlisted commands succeed with no
+// output; a result list is consumed in order and its last entry repeats.
+type recordingRunner struct {
+	commands []string
+	results  map[string][]commandResult
+}
+
+type commandResult struct {
+	output string
+	err    error
+}
+
+func (r *recordingRunner) CombinedOutput(name string, args ...string) ([]byte, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	r.commands = append(r.commands, line)
+	results := r.results[line]
+	if len(results) == 0 {
+		return nil, nil
+	}
+	result := results[0]
+	if len(results) > 1 {
+		r.results[line] = results[1:]
+	}
+	return []byte(result.output), result.err
+}
+
+func TestUninstallRKE2(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "rke2-uninstall.sh")
+	if err := os.WriteFile(script, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	present := filepath.Join(dir, "rke2")
+	if err := os.Mkdir(present, 0755); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "kubelet")
+
+	origScript, origDirs := rke2UninstallScript, rke2DataDirs
+	rke2UninstallScript, rke2DataDirs = script, []string{present, missing}
+	defer func() { rke2UninstallScript, rke2DataDirs = origScript, origDirs }()
+
+	rec := &recordingRunner{results: map[string][]commandResult{
+		script: {{output: "partial", err: errors.New("exit status 1")}},
+	}}
+	defer SetCommandRunner(rec)()
+
+	if err := UninstallRKE2(); err != nil {
+		t.Fatalf("UninstallRKE2 should continue past script errors, got %v", err)
+	}
+
+	want := []string{script, "rm -rf " + present}
+	if !reflect.DeepEqual(rec.commands, want) {
+		t.Errorf("commands = %q, want %q", rec.commands, want)
+	}
+}
+
+func TestStopLonghornProcesses(t *testing.T) {
+	origGrace := longhornTermGrace
+	longhornTermGrace = 0
+	defer func() { longhornTermGrace = origGrace }()
+
+	notRunning := commandResult{err: errors.New("exit status 1")}
+
+	tests := []struct {
+		name    string
+		results map[string][]commandResult
+		want    []string
+	}{
+		{
+			name: "nothing running",
+			results: map[string][]commandResult{
+				"pgrep -x longhorn-engine":           {notRunning},
+				"pgrep -x longhorn-instance-manager": {notRunning},
+				"pgrep -x longhorn-manager":          {notRunning},
+			},
+			want: []string{
+				"pgrep -x longhorn-engine",
+				"pgrep -x longhorn-instance-manager",
+				"pgrep -x longhorn-manager",
+			},
+		},
+		{
+			name: "exits on TERM",
+			results: map[string][]commandResult{
+				"pgrep -x longhorn-engine":           {notRunning},
+				"pgrep -x longhorn-instance-manager": {notRunning},
+				"pgrep -x longhorn-manager":          {{}, notRunning},
+			},
+			want: []string{
+				"pgrep -x longhorn-engine",
+				"pgrep -x longhorn-instance-manager",
+				"pgrep -x longhorn-manager",
+				"pkill -TERM -x longhorn-engine",
+				"pkill -TERM -x longhorn-instance-manager",
+				"pkill -TERM -x longhorn-manager",
+				"pgrep -x longhorn-engine",
+				"pgrep -x longhorn-instance-manager",
+				"pgrep -x longhorn-manager",
+			},
+		},
+		{
+			name: "survives TERM",
+			results: map[string][]commandResult{
+				"pgrep -x longhorn-engine": {{}},
+			},
+			want: []string{
+				"pgrep -x longhorn-engine",
+				"pkill -TERM -x longhorn-engine",
+				"pkill -TERM -x longhorn-instance-manager",
+				"pkill -TERM -x longhorn-manager",
+				"pgrep -x longhorn-engine",
+				"pkill -KILL -x longhorn-engine",
+				"pkill -KILL -x longhorn-instance-manager",
+				"pkill -KILL -x longhorn-manager",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingRunner{results: tt.results}
+			defer SetCommandRunner(rec)()
+
+			stopLonghornProcesses()
+
+			if !reflect.DeepEqual(rec.commands, tt.want) {
+				t.Errorf("commands =\n%s\nwant\n%s", strings.Join(rec.commands, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestPlanCleanup(t *testing.T) {
+	state := cleanupState{
+		fstab: strings.Join([]string{
+			"UUID=root / ext4 defaults 0 1",
+			"UUID=aaa /mnt/disk0 ext4 defaults,nofail 0 2 # managed by cluster-bloom",
+			"UUID=bbb /mnt/disk1 ext4 defaults,nofail 0 2 # managed by cluster-bloom",
+			"/dev/sdd /mnt/data ext4 defaults 0 2 # premounted by cluster-bloom",
+		}, "\n"),
+		mounts: strings.Join([]string{
+			"/dev/sda2 / ext4 rw 0 0",
+			"/dev/nvme0n1 /mnt/disk0 ext4 rw 0 0",
+			"/dev/mapper/bloom-disk1 /mnt/disk1 ext4 rw 0 0",
+			"/dev/sdd /mnt/data ext4 rw 0 0",
+			"/dev/longhorn/pvc-1 /var/lib/kubelet/pods/x/volumes/kubernetes.io~csi/pvc-1/mount ext4 rw 0 0",
+			"tmpfs /var/lib/kubelet/pods/y/volumes/kubernetes.io~projected/token tmpfs rw 0 0",
+		}, "\n"),
+		crypttab: "bloom-disk1 UUID=ccc /etc/bloom/disk.key luks\nhome UUID=ddd none luks\n",
+		lsblk:    "sda  disk\nsdd  disk /mnt/data\nnvme0n1 disk\n",
+		mappers:  []string{"/dev/mapper/bloom-disk1"},
+	}
+
+	plan := planCleanup("/dev/nvme0n1, /dev/nvme1n1", state)
+
+	wantUnmounts := []string{"/mnt/disk0", "/mnt/disk1", "/var/lib/kubelet/pods/x/volumes/kubernetes.io~csi/pvc-1/mount"}
+	if !reflect.DeepEqual(plan.Unmounts, wantUnmounts) {
+		t.Errorf("Unmounts = %q, want %q", plan.Unmounts, wantUnmounts)
+	}
+	if len(plan.FstabRemovals) != 2 || !strings.Contains(plan.FstabRemovals[0], "/mnt/disk0") || !strings.Contains(plan.FstabRemovals[1], "/mnt/disk1") {
+		t.Errorf("FstabRemovals = %q, want the two managed cluster disk lines", plan.FstabRemovals)
+	}
+	if want := []string{"bloom-disk1 UUID=ccc /etc/bloom/disk.key luks"}; !reflect.DeepEqual(plan.CrypttabRemovals, want) {
+		t.Errorf("CrypttabRemovals = %q, want %q", plan.CrypttabRemovals, want)
+	}
+	if want := []string{"bloom-disk1"}; !reflect.DeepEqual(plan.LUKSMappings, want) {
+		t.Errorf("LUKSMappings = %q, want %q", plan.LUKSMappings, want)
+	}
+	if want := []string{"/dev/nvme0n1", "/dev/nvme1n1"}; !reflect.DeepEqual(plan.WipedDevices, want) {
+		t.Errorf("WipedDevices = %q, want %q", plan.WipedDevices, want)
+	}
+	if len(plan.RemovedDirs) != 0 {
+		t.Errorf("RemovedDirs = %q, want none without a /var/lib/rancher mount", plan.RemovedDirs)
+	}
+}
+
+func TestOrderClusterDisks(t *testing.T) {
+	// The order of sort -V, which prepare_node/disk_facts.yaml uses
+	want := []string{"/dev/nvme2n1", "/dev/nvme10n1", "/dev/sdaa", "/dev/sdb", "/dev/sdc"}
+	for _, clusterDisks := range []string{
+		"/dev/nvme2n1,/dev/nvme10n1,/dev/sdb,/dev/sdc,/dev/sdaa",
+		"/dev/sdaa,/dev/sdc,/dev/sdb,/dev/nvme10n1,/dev/nvme2n1",
+		" /dev/sdc, /dev/nvme10n1,,/dev/sdaa,/dev/nvme2n1,/dev/sdb,/dev/sdc ",
+	} {
+		if got := orderClusterDisks(clusterDisks); !reflect.DeepEqual(got, want) {
+			t.Errorf("orderClusterDisks(%q) = %q, want %q", clusterDisks, got, want)
+		}
+	}
+	if got := countClusterDisksStr("/dev/sdb,/dev/sdb, "); got != 1 {
+		t.Errorf("countClusterDisksStr counted %d disks, want 1", got)
+	}
+	if got := orderClusterDisks(""); got != nil {
+		t.Errorf("orderClusterDisks(\"\") = %q, want nil", got)
+	}
+}
@@ -0,0 +1,27 @@
+package runtime
+
+import "os/exec"
+
+// CommandRunner runs an external command and returns its combined output.
+// Host-side cleanup goes through it rather than os/exec, so tests can assert
+// the exact command sequence without touching the system.
+type CommandRunner interface {
+	CombinedOutput(name string, args ...string) ([]byte, error)
+}
+
+type execRunner struct{}
+
+func (execRunner) CombinedOutput(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).CombinedOutput()
+}
+
+// runner is the CommandRunner used by the cleanup functions
+var runner CommandRunner = execRunner{}
+
+// SetCommandRunner replaces the runner used by the cleanup functions and
+// returns a function that restores the previous one
+func SetCommandRunner(r CommandRunner) (restore func()) {
+	previous := runner
+	runner = r
+	return func() { runner = previous }
+}
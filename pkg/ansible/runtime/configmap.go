@@ -0,0 +1,34 @@
+package runtime
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// BloomConfigMap returns the data of the bloom ConfigMap the deployment
+// creates in the default namespace
+func BloomConfigMap(kubeconfig string) (map[string]string, error) {
+	var cm struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := kubectlJSON(kubectlCommand(kubeconfig), &cm, "get", "configmap", "bloom", "-n", "default"); err != nil {
+		return nil, err
+	}
+	return cm.Data, nil
+}
+
+// BloomSecretKey returns the "key" entry of the named Secret in the default
+// namespace, where the deployment stores the config redaction key
+func BloomSecretKey(kubeconfig, name string) ([]byte, error) {
+	var secret struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := kubectlJSON(kubectlCommand(kubeconfig), &secret, "get", "secret", name, "-n", "default"); err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(secret.Data["key"])
+	if err == nil && len(key) == 0 {
+		err = fmt.Errorf("secret %s has no key", name)
+	}
+	return key, err
+}
@@ -0,0 +1,178 @@
+package runtime
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DiagnosticsOptions configures CollectDiagnostics
+type DiagnosticsOptions struct {
+	Dir     string   // Directory with bloom.log and its archives; the tarball is written here
+	GPUNode bool     // Also collect rocm-smi output
+	Config  []byte   // Redacted bloom.yaml to include, if any
+	Secrets []string // Literal values to scrub from every collected file (JOIN_TOKEN, ...)
+}
+
+// diagnosticCommand is a command whose output is stored in the bundle as file
+type diagnosticCommand struct {
+	file string
+	name string
+	args []string
+}
+
+// diagnosticCommands are run on every node; a failing command (e.g. no
+// rke2-agent unit on a server) is recorded in its output file
+var diagnosticCommands = []diagnosticCommand{
+	{"journal/rke2-server.log", "journalctl", []string{"-u", "rke2-server", "--no-pager", "-n", "20000"}},
+	{"journal/rke2-agent.log", "journalctl", []string{"-u", "rke2-agent", "--no-pager", "-n", "20000"}},
+	{"node/lsblk.txt", "lsblk", []string{"-o", "NAME,SIZE,TYPE,FSTYPE,MOUNTPOINT,UUID,MODEL"}},
+	{"node/mount.txt", "mount", nil},
+}
+
+var gpuDiagnosticCommands = []diagnosticCommand{
+	{"node/rocm-smi.txt", "rocm-smi", nil},
+	{"node/rocm-smi-showallinfo.txt", "rocm-smi", []string{"--showallinfo"}},
+}
+
+// rke2ConfigPath is the RKE2 config collected (token lines redacted)
+var rke2ConfigPath = "/etc/rancher/rke2/config.yaml"
+
+// secretAssignmentPattern matches key: value, key=value and "key": "value"
+// assignments whose key ends in token, password or secret (JOIN_TOKEN,
+// agent-token, DOCKERHUB_TOKEN, ...) so their value can be replaced
+var secretAssignmentPattern = regexp.MustCompile(`(?i)("?[A-Za-z0-9_-]*(?:token|password|secret)"?\s*[:=]\s*)("[^"]*"|'[^']*'|[^\s,}]+)`)
+
+// rke2TokenPattern matches RKE2 join tokens wherever they appear in logs
+var rke2TokenPattern = regexp.MustCompile(`K10[0-9a-f]+::[^\s"',]+`)
+
+const redactedValue = "<redacted>"
+
+// CollectDiagnostics writes bloom-diagnostics-<timestamp>.tar.gz to opts.Dir
+// with the bloom logs and run summary, the RKE2 config, the RKE2 journal,
+// block device and mount snapshots, and rocm-smi output on GPU nodes. Every
+// file is scrubbed of opts.Secrets and token-like values. Sources that cannot be read are listed
+// in MISSING.txt instead of failing the collection.
+func CollectDiagnostics(opts DiagnosticsOptions) (string, error) {
+	timestamp := time.Now().Format("20060102-150405")
+	base := "bloom-diagnostics-" + timestamp
+	files := make(map[string][]byte)
+	var missing []string
+
+	logs, _ := filepath.Glob(filepath.Join(opts.Dir, "bloom-*.log"))
+	logs = append([]string{filepath.Join(opts.Dir, "bloom.log"), filepath.Join(opts.Dir, SummaryFileName)}, logs...)
+	for _, path := range logs {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			missing = append(missing, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		files["logs/"+filepath.Base(path)] = data
+	}
+
+	secrets := append([]string(nil), opts.Secrets...)
+	if data, err := os.ReadFile(rke2ConfigPath); err != nil {
+		missing = append(missing, fmt.Sprintf("%s: %v", rke2ConfigPath, err))
+	} else {
+		secrets = append(secrets, rke2ConfigTokens(data)...)
+		files["rke2/config.yaml"] = data
+	}
+	if len(opts.Config) > 0 {
+		files["bloom.yaml"] = opts.Config
+	}
+
+	commands := diagnosticCommands
+	if opts.GPUNode {
+		commands = append(append([]diagnosticCommand(nil), commands...), gpuDiagnosticCommands...)
+	}
+	for _, c := range commands {
+		out, err := runner.CombinedOutput(c.name, c.args...)
+		if err != nil {
+			out = append(out, fmt.Sprintf("\n# %s %s: %v\n", c.name, strings.Join(c.args, " "), err)...)
+		}
+		files[c.file] = out
+	}
+	if len(missing) > 0 {
+		files["MISSING.txt"] = []byte(strings.Join(missing, "\n") + "\n")
+	}
+
+	path := filepath.Join(opts.Dir, base+".tar.gz")
+	if err := writeDiagnosticsTarball(path, base, files, secrets); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// rke2ConfigTokens returns the token and agent-token values of an RKE2
+// config.yaml, so they are also scrubbed where they appear in the journal
+func rke2ConfigTokens(data []byte) []string {
+	var tokens []string
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, found := strings.Cut(line, ":")
+		key = strings.TrimSpace(key)
+		if !found || (key != "token" && key != "agent-token") {
+			continue
+		}
+		if value = strings.Trim(strings.TrimSpace(value), `"'`); value != "" {
+			tokens = append(tokens, value)
+		}
+	}
+	return tokens
+}
+
+// redactDiagnostics replaces secrets and token-like values in data. Longer
+// secrets are replaced first so a secret containing another is fully removed.
+func redactDiagnostics(data []byte, secrets []string) []byte {
+	sorted := append([]string(nil), secrets...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	text := string(data)
+	for _, secret := range sorted {
+		if secret != "" {
+			text = strings.ReplaceAll(text, secret, redactedValue)
+		}
+	}
+	text = secretAssignmentPattern.ReplaceAllString(text, "${1}"+redactedValue)
+	text = rke2TokenPattern.ReplaceAllString(text, redactedValue)
+	return []byte(text)
+}
+
+func writeDiagnosticsTarball(path, base string, files map[string][]byte, secrets []string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	now := time.Now()
+	for _, name := range names {
+		data := redactDiagnostics(files[name], secrets)
+		header := &tar.Header{Name: base + "/" + name, Mode: 0600, Size: int64(len(data)), ModTime: now}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,83 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// minEntropyBits is the kernel entropy pool size below which key generation
+// may block. Kernels 5.18 and newer always report 256 once the RNG is seeded.
+const minEntropyBits = 256
+
+const entropyAvailPath = "/proc/sys/kernel/random/entropy_avail"
+
+// entropyDaemons are the services that keep the entropy pool filled
+var entropyDaemons = []string{"haveged", "rngd", "rng-tools"}
+
+// requireEntropy fails a run on low entropy instead of only warning
+var requireEntropy bool
+
+// SetRequireEntropy makes subsequent playbook runs fail, rather than warn,
+// when the host has too little entropy to generate the ephemeral SSH key
+func SetRequireEntropy(require bool) {
+	requireEntropy = require
+}
+
+// readEntropyAvail returns the entropy pool size reported at path
+func readEntropyAvail(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	bits, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return bits, nil
+}
+
+// activeEntropyDaemons lists the entropy daemons systemd reports as running
+func activeEntropyDaemons() []string {
+	var active []string
+	for _, svc := range entropyDaemons {
+		if exec.Command("systemctl", "is-active", "--quiet", svc).Run() == nil {
+			active = append(active, svc)
+		}
+	}
+	return active
+}
+
+// lowEntropyMessage describes a pool of bits below minEntropyBits, or returns
+// "" when there is enough entropy
+func lowEntropyMessage(bits int, daemons []string) string {
+	if bits >= minEntropyBits {
+		return ""
+	}
+	msg := fmt.Sprintf("Only %d bits of entropy are available (recommended: at least %d). SSH key and certificate generation may hang waiting for the kernel RNG.", bits, minEntropyBits)
+	if len(daemons) == 0 {
+		return msg + " No entropy daemon is running; install and start haveged or rng-tools (rngd), or give the VM a virtio-rng device."
+	}
+	return msg + fmt.Sprintf(" Running entropy daemon(s): %s.", strings.Join(daemons, ", "))
+}
+
+// checkEntropy warns about low host entropy before bloom generates its SSH
+// key, and returns an error instead when REQUIRE_ENTROPY is set. An
+// unreadable entropy_avail (non-Linux, restricted /proc) is not checked.
+func checkEntropy() error {
+	bits, err := readEntropyAvail(entropyAvailPath)
+	if err != nil {
+		return nil
+	}
+	msg := lowEntropyMessage(bits, activeEntropyDaemons())
+	if msg == "" {
+		return nil
+	}
+	if requireEntropy {
+		return fmt.Errorf("%s Set REQUIRE_ENTROPY: false to continue with a warning instead", msg)
+	}
+	fmt.Printf("⚠️  WARNING: %s\n", msg)
+	return nil
+}
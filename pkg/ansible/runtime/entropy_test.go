@@ -0,0 +1,52 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadEntropyAvail(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // Empty means no file
+		want    int
+		wantErr bool
+	}{
+		{name: "seeded", content: "256\n", want: 256},
+		{name: "low", content: "37\n", want: 37},
+		{name: "garbage", content: "n/a\n", wantErr: true},
+		{name: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "entropy_avail")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := readEntropyAvail(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readEntropyAvail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("readEntropyAvail() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLowEntropyMessage(t *testing.T) {
+	if msg := lowEntropyMessage(minEntropyBits, nil); msg != "" {
+		t.Errorf("lowEntropyMessage(%d) = %q, want no message", minEntropyBits, msg)
+	}
+	if msg := lowEntropyMessage(100, nil); !strings.Contains(msg, "Only 100 bits") || !strings.Contains(msg, "haveged or rng-tools") {
+		t.Errorf("lowEntropyMessage(100, nil) = %q, want low entropy and daemon hint", msg)
+	}
+	if msg := lowEntropyMessage(100, []string{"rngd"}); !strings.Contains(msg, "Running entropy daemon(s): rngd") {
+		t.Errorf("lowEntropyMessage(100, [rngd]) = %q, want running daemon listed", msg)
+	}
+}
@@ -0,0 +1,88 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// EventType identifies the kind of progress event written to the events stream
+type EventType string
+
+const (
+	EventStepStarted   EventType = "step_started"
+	EventStepCompleted EventType = "step_completed"
+	EventStepFailed    EventType = "step_failed"
+	EventLog           EventType = "log"
+	EventWarning       EventType = "warning"
+	EventProgress      EventType = "progress"
+)
+
+// eventsFD is the file descriptor the events stream is handed to the child on
+// (the first entry of exec.Cmd.ExtraFiles)
+const eventsFD = 3
+
+// Event is a single newline-delimited JSON progress event
+type Event struct {
+	Type    EventType  `json:"type"`
+	Time    time.Time  `json:"time"`
+	Step    string     `json:"step,omitempty"`
+	Status  TaskStatus `json:"status,omitempty"`
+	Message string     `json:"message,omitempty"`
+	// DurationMs is how long the step ran, set on step_completed and step_failed
+	DurationMs int64 `json:"duration_ms,omitempty"`
+	// Level is info, warning or error, set on log events
+	Level string `json:"level,omitempty"`
+	// Progress is the overall deployment progress, set on progress events
+	Progress *Progress `json:"progress,omitempty"`
+}
+
+// EventWriter serialises events as newline-delimited JSON. It is safe for
+// concurrent use since stdout and stderr are processed in separate goroutines.
+type EventWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewEventWriter creates an event writer on top of w
+func NewEventWriter(w io.Writer) *EventWriter {
+	return &EventWriter{encoder: json.NewEncoder(w)}
+}
+
+// Emit writes a single event. Write errors are ignored so a reader that goes
+// away never interrupts the deployment.
+func (e *EventWriter) Emit(event Event) {
+	if e == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.encoder.Encode(event)
+}
+
+var eventsPath string
+
+// SetEventsOutput configures the file or named pipe that progress events are
+// written to for subsequent playbook runs. An empty path disables events.
+func SetEventsOutput(path string) {
+	eventsPath = path
+}
+
+// openEventsOutput opens the configured events target on the host, before the
+// container is entered, so named pipes and /dev/fd/N paths resolve correctly
+func openEventsOutput() (*os.File, error) {
+	if eventsPath == "" {
+		return nil, nil
+	}
+	f, err := os.OpenFile(eventsPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open events output %s: %w", eventsPath, err)
+	}
+	return f, nil
+}
@@ -4,12 +4,15 @@ package runtime
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/silogen/cluster-bloom/pkg/ssh"
 	"golang.org/x/sys/unix"
@@ -17,13 +20,7 @@ import (
 
 func RunContainer(rootfs, playbookDir, playbook string, extraArgs []string, dryRun bool, tags string, outputMode OutputMode) int {
 	// Detect the actual user (not root if using sudo)
-	actualUser := os.Getenv("SUDO_USER")
-	if actualUser == "" {
-		actualUser = os.Getenv("USER")
-	}
-	if actualUser == "" {
-		actualUser = "ubuntu"
-	}
+	actualUser := ActualUser()
 
 	// Get current working directory to pass to child for log file
 	cwd, err := os.Getwd()
@@ -32,14 +29,28 @@ func RunContainer(rootfs, playbookDir, playbook string, extraArgs []string, dryR
 		cwd = ""
 	}
 
+	// Low entropy makes the ED25519 key generation below block
+	if err := checkEntropy(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
 	// Setup ephemeral SSH key on HOST before starting container
 	fmt.Printf("🔑 Setting up ephemeral SSH key...\n")
-	sshManager, err := ssh.NewEphemeralSSHManager(cwd, actualUser)
+	sshManager, err := ssh.NewEphemeralSSHManager(cwd, actualUser, reuseSSHKey)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create SSH manager: %v\n", err)
 		return 1
 	}
-	if err := sshManager.Setup(); err != nil {
+	err = sshManager.Setup()
+	if errors.Is(err, ssh.ErrKeyExists) && reuseSSHKey {
+		// The stale key's files are gone, so it cannot be reused: replace it
+		fmt.Printf("   Replacing the stale bloom key of a previous run...\n")
+		if err = sshManager.ForceCleanup(); err == nil {
+			err = sshManager.Setup()
+		}
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to setup ephemeral SSH on host: %v\n", err)
 		return 1
 	}
@@ -67,13 +78,30 @@ func RunContainer(rootfs, playbookDir, playbook string, extraArgs []string, dryR
 	if tags != "" {
 		childArgs = append(childArgs, "--tags", tags)
 	}
+
+	// Open the events stream on the host and hand it to the child as fd 3
+	eventsFile, err := openEventsOutput()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up events output: %v\n", err)
+		return 1
+	}
+	if eventsFile != nil {
+		defer eventsFile.Close()
+		childArgs = append(childArgs, "--events-json")
+	}
 	childArgs = append(childArgs, extraArgs...)
 
 	cmd := exec.Command("/proc/self/exe", childArgs...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
+	if outputMode == OutputJSON && jsonStdout != nil {
+		cmd.Stdout = jsonStdout
+	}
 	cmd.Stderr = os.Stderr
 	cmd.Env = os.Environ()
+	if eventsFile != nil {
+		cmd.ExtraFiles = []*os.File{eventsFile}
+	}
 
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		Cloneflags:   syscall.CLONE_NEWUTS | syscall.CLONE_NEWPID | syscall.CLONE_NEWNS,
@@ -98,9 +126,10 @@ func RunChild() {
 	workDir := os.Args[6]
 	outputMode := OutputMode(os.Args[7])
 
-	// Check if --dry-run and --tags flags are present
+	// Check if --dry-run, --tags and --events-json flags are present
 	dryRun := false
 	tags := ""
+	eventsEnabled := false
 	extraArgs := []string{}
 	for i := 8; i < len(os.Args); i++ {
 		if os.Args[i] == "--dry-run" {
@@ -108,6 +137,8 @@ func RunChild() {
 		} else if os.Args[i] == "--tags" && i+1 < len(os.Args) {
 			tags = os.Args[i+1]
 			i++ // Skip next arg
+		} else if os.Args[i] == "--events-json" {
+			eventsEnabled = true
 		} else {
 			extraArgs = append(extraArgs, os.Args[i])
 		}
@@ -192,10 +223,10 @@ func RunChild() {
 
 	ansibleArgs := []string{
 		"--connection=ssh",
-		"--inventory=127.0.0.1,",
+		"--inventory=" + inventoryHost + ",",
 		"--user=" + username,
 		"--become",
-		"--ssh-extra-args=-o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -o IdentitiesOnly=yes -i /root/.ssh/id_ephemeral",
+		"--ssh-extra-args=" + sshCommonArgs + " -i /root/.ssh/id_ephemeral",
 		"-v",
 	}
 	if tags != "" {
@@ -229,10 +260,47 @@ func RunChild() {
 
 	// Create output processor
 	processor := NewOutputProcessor(outputMode, logFile, configMap)
+	processor.SetDryRun(dryRun)
+	processor.SetLogFormat(configMap[logFormatKey])
+	processor.SetLogLevel(configMap[logLevelKey])
+
+	// Record the checkpoint a later --resume compares the config against
+	if line := resumeKeysLine(extraArgs); line != "" {
+		processor.writeLog(line)
+	}
+	if eventsEnabled {
+		eventsFile := os.NewFile(eventsFD, "events")
+		defer eventsFile.Close()
+		processor.SetEvents(NewEventWriter(eventsFile))
+	}
 
 	cmd := exec.Command("ansible-playbook", ansibleArgs...)
 	cmd.Stdin = os.Stdin
 
+	// Abort the playbook when a phase exceeds its PREK8S/K8S/POSTK8S_TIMEOUT
+	phaseTimer := NewPhaseTimer(configMap, func(phase string, limit time.Duration) {
+		fmt.Fprintf(os.Stderr, "\n⏱️  Phase %s exceeded its time budget of %s - stopping the deployment\n", phase, limit)
+		if cmd.Process != nil {
+			cmd.Process.Signal(syscall.SIGTERM)
+		}
+	})
+	if phaseTimer != nil {
+		defer phaseTimer.Stop()
+		processor.SetPhaseTimer(phaseTimer)
+	}
+
+	// Abort the playbook when a single task runs longer than TASK_TIMEOUT
+	taskTimer := NewTaskTimer(configMap, func(task string, limit time.Duration) {
+		fmt.Fprintf(os.Stderr, "\n⏱️  Task %q exceeded the TASK_TIMEOUT of %s - stopping the deployment\n", task, limit)
+		if cmd.Process != nil {
+			cmd.Process.Signal(syscall.SIGTERM)
+		}
+	})
+	if taskTimer != nil {
+		defer taskTimer.Stop()
+		processor.SetTaskTimer(taskTimer)
+	}
+
 	// Use pipes to capture and process output
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
@@ -252,9 +320,18 @@ func RunChild() {
 		os.Exit(1)
 	}
 
-	// Process output streams
-	go processor.ProcessStream(stdoutPipe, os.Stdout)
-	go processor.ProcessStream(stderrPipe, os.Stderr)
+	// Process output streams. Wait reads nothing after the process exits, so
+	// the streams are drained first to keep the last task results.
+	var streams sync.WaitGroup
+	streams.Add(2)
+	go func() {
+		defer streams.Done()
+		processor.ProcessStream(stdoutPipe, os.Stdout)
+	}()
+	go func() {
+		defer streams.Done()
+		processor.ProcessStream(stderrPipe, os.Stderr)
+	}()
 
 	cmd.Env = []string{
 		"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
@@ -265,14 +342,42 @@ func RunChild() {
 	}
 
 	// Wait for command to complete
-	if err := cmd.Wait(); err != nil {
+	streams.Wait()
+	processor.Flush(os.Stdout)
+	waitErr := cmd.Wait()
+	exitCode := 0
+	if exitErr, ok := waitErr.(*exec.ExitError); ok {
+		exitCode = exitErr.ExitCode()
+	} else if waitErr != nil {
+		exitCode = 1
+	}
+
+	// Record the outcome for automation next to bloom.log
+	if workDir != "" {
+		summaryPath := "/host" + workDir + "/" + SummaryFileName
+		if err := processor.WriteRunSummary(summaryPath, playbook, exitCode); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if waitErr != nil {
 		// Print summary before exiting (if clean mode)
 		processor.PrintSummary()
 
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			os.Exit(exitErr.ExitCode())
+		if phaseTimer != nil {
+			if phase, limit, expired := phaseTimer.Expired(); expired {
+				fmt.Fprintf(os.Stderr, "❌ Deployment stopped: phase %s did not finish within %s (%s)\n", phase, limit, phaseTimeoutKeys[phase])
+				os.Exit(1)
+			}
 		}
-		os.Exit(1)
+		if taskTimer != nil {
+			if task, limit, expired := taskTimer.Expired(); expired {
+				fmt.Fprintf(os.Stderr, "❌ Deployment stopped: task %q exceeded timeout of %s (%s)\n", task, limit, taskTimeoutKey)
+				os.Exit(1)
+			}
+		}
+
+		os.Exit(exitCode)
 	}
 
 	// Print summary on success (if clean mode)
@@ -355,6 +460,19 @@ func parseConfigFromExtraArgs(extraArgs []string) map[string]string {
 				if val, ok := varMap["DOMAIN"].(string); ok {
 					config["DOMAIN"] = val
 				}
+				for _, key := range phaseTimeoutKeys {
+					if val, ok := varMap[key].(string); ok {
+						config[key] = val
+					}
+				}
+				if val, ok := varMap[taskTimeoutKey].(string); ok {
+					config[taskTimeoutKey] = val
+				}
+				for _, key := range []string{logFormatKey, logLevelKey} {
+					if val, ok := varMap[key].(string); ok {
+						config[key] = val
+					}
+				}
 			}
 			i++ // Skip the next argument as we've already processed it
 		}
@@ -0,0 +1,52 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// inventoryHost is the only target bloom deploys to: the local node, reached
+// over SSH from inside the runtime container
+const inventoryHost = "127.0.0.1"
+
+// sshCommonArgs are the SSH options bloom passes to ansible for the target connection
+const sshCommonArgs = "-o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -o IdentitiesOnly=yes"
+
+// ActualUser returns the user that invoked bloom, looking through sudo
+func ActualUser() string {
+	if user := os.Getenv("SUDO_USER"); user != "" {
+		return user
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return "ubuntu"
+}
+
+// BuildInventory renders the INI inventory equivalent to the connection
+// settings bloom passes to ansible-playbook. privateKeyPath may be empty to
+// fall back to the caller's default SSH keys.
+func BuildInventory(username, privateKeyPath string) string {
+	var b strings.Builder
+	b.WriteString("# Inventory used by bloom: the local node over SSH, with privilege escalation.\n")
+	b.WriteString("# bloom authenticates with an ephemeral key that is removed after each run;\n")
+	b.WriteString("# set ansible_ssh_private_key_file to a key authorized for this user.\n")
+	b.WriteString("# The host is named localhost so exported playbooks (hosts: localhost) match it.\n")
+	b.WriteString("[all]\n")
+	fmt.Fprintf(&b, "localhost ansible_host=%s ansible_connection=ssh ansible_user=%s ansible_become=true ansible_ssh_common_args='%s'",
+		inventoryHost, username, sshCommonArgs)
+	if privateKeyPath != "" {
+		fmt.Fprintf(&b, " ansible_ssh_private_key_file=%s", privateKeyPath)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// WriteInventory writes the inventory bloom would use for the invoking user to path
+func WriteInventory(path string) error {
+	if err := os.WriteFile(path, []byte(BuildInventory(ActualUser(), "")), 0644); err != nil {
+		return fmt.Errorf("write inventory %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package runtime
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// logFormatKey selects the bloom.log format: text (the raw Ansible output) or
+// json (one logRecord per line, for shipping to Loki or ELK)
+const logFormatKey = "LOG_FORMAT"
+
+// logLevelKey is the lowest level of log events written to the events
+// stream: debug, info, warn or error. bloom.log always gets every line.
+const logLevelKey = "LOG_LEVEL"
+
+// Levels assigned to Ansible output lines
+const (
+	levelDebug   = "debug"
+	levelInfo    = "info"
+	levelWarning = "warning"
+	levelError   = "error"
+)
+
+// levelRanks orders the levels; LOG_LEVEL spells warning as warn
+var levelRanks = map[string]int{
+	levelDebug:   0,
+	levelInfo:    1,
+	"warn":       2,
+	levelWarning: 2,
+	levelError:   3,
+}
+
+// logRecord is a bloom.log line in the json format
+type logRecord struct {
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	Task  string    `json:"task,omitempty"`
+	Msg   string    `json:"msg"`
+}
+
+// lineLevel classifies a raw Ansible output line. Play, task, result and
+// recap lines and bloom's resume checkpoint are info; the rest (-v result bodies, multi-line messages) is
+// debug. Failures Ansible ignores (ignore_errors) stay at info.
+func lineLevel(line string) string {
+	if info, ok := ParseTaskResult(line); ok && (info.Status == TaskStatusFailed || info.Status == TaskStatusUnreachable) && !IsIgnoredError(line) {
+		return levelError
+	}
+	if strings.HasPrefix(strings.TrimSpace(line), "ERROR!") {
+		return levelError
+	}
+	if _, ok := ParseWarning(line); ok {
+		return levelWarning
+	}
+	if _, ok := ParseTaskHeader(line); ok {
+		return levelInfo
+	}
+	if _, ok := ParseTaskResult(line); ok {
+		return levelInfo
+	}
+	if strings.HasPrefix(line, "PLAY ") || strings.Contains(line, " unreachable=") || strings.HasPrefix(line, resumeKeysPrefix) {
+		return levelInfo
+	}
+	return levelDebug
+}
+
+// levelEnabled reports whether a line at level passes the minimum level min.
+// An empty or unknown min lets everything through.
+func levelEnabled(level, min string) bool {
+	minRank, ok := levelRanks[min]
+	return !ok || levelRanks[level] >= minRank
+}
+
+// formatLogLine returns line as written to bloom.log in format. task is the
+// task the line belongs to, empty before the first task header.
+func formatLogLine(format, task, line string) string {
+	if format != "json" {
+		return line
+	}
+	data, err := json.Marshal(logRecord{Time: time.Now().UTC(), Level: lineLevel(line), Task: task, Msg: line})
+	if err != nil {
+		return line
+	}
+	return string(data)
+}
+
+// parseLogLine returns the Ansible output line and level of a bloom.log line
+// written in either format, so readers of the log need not know LOG_FORMAT
+func parseLogLine(line string) (msg, level string) {
+	if strings.HasPrefix(line, "{") {
+		var record logRecord
+		if err := json.Unmarshal([]byte(line), &record); err == nil && record.Level != "" {
+			return record.Msg, record.Level
+		}
+	}
+	return line, lineLevel(line)
+}
@@ -0,0 +1,98 @@
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLogLineFormats(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		level string
+	}{
+		{name: "task header", line: "TASK [Install RKE2] ****", level: levelInfo},
+		{name: "ok result", line: "ok: [127.0.0.1] => {\"changed\": false}", level: levelInfo},
+		{name: "fatal", line: "fatal: [127.0.0.1]: FAILED! => {\"msg\": \"boom\"}", level: levelError},
+		{name: "ansible warning", line: "[WARNING]: Could not match supplied host pattern", level: levelWarning},
+		{name: "playbook error", line: "ERROR! the role 'x' was not found", level: levelError},
+		{name: "result body", line: `    "stdout": "rke2 version v1.31.4"`, level: levelDebug},
+		{name: "play recap", line: "127.0.0.1 : ok=12 changed=3 unreachable=0 failed=0 skipped=1", level: levelInfo},
+		{name: "resume checkpoint", line: resumeKeysPrefix + `{"FIRST_NODE":true}`, level: levelInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatLogLine("text", "Install RKE2", tt.line); got != tt.line {
+				t.Errorf("text format changed the line: %q", got)
+			}
+			if msg, level := parseLogLine(tt.line); msg != tt.line || level != tt.level {
+				t.Errorf("parseLogLine(text) = %q, %q, want %q, %q", msg, level, tt.line, tt.level)
+			}
+
+			encoded := formatLogLine("json", "Install RKE2", tt.line)
+			var record logRecord
+			if err := json.Unmarshal([]byte(encoded), &record); err != nil {
+				t.Fatalf("json line %q does not decode: %v", encoded, err)
+			}
+			if record.Task != "Install RKE2" || record.Time.IsZero() {
+				t.Errorf("json record = %+v", record)
+			}
+			if msg, level := parseLogLine(encoded); msg != tt.line || level != tt.level {
+				t.Errorf("parseLogLine(json) = %q, %q, want %q, %q", msg, level, tt.line, tt.level)
+			}
+		})
+	}
+}
+
+func TestLogLevelFiltersEvents(t *testing.T) {
+	output := strings.Join([]string{
+		"TASK [Install RKE2] ****",
+		`changed: [127.0.0.1] => {"changed": true,`,
+		`    "stdout": "installed"`,
+		"}",
+		"[WARNING]: Could not match supplied host pattern",
+		`fatal: [127.0.0.1]: FAILED! => {"msg": "boom"}`,
+	}, "\n")
+
+	tests := []struct {
+		level string
+		want  []string
+	}{
+		{level: "debug", want: []string{levelDebug, levelDebug, levelWarning}},
+		{level: "info", want: []string{levelWarning}},
+		{level: "warn", want: []string{levelWarning}},
+		{level: "error", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			var events bytes.Buffer
+			p := NewOutputProcessor(OutputVerbose, nil, nil)
+			p.SetEvents(NewEventWriter(&events))
+			p.SetLogLevel(tt.level)
+			if err := p.ProcessStream(strings.NewReader(output), io.Discard); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			scanner := bufio.NewScanner(&events)
+			for scanner.Scan() {
+				var event Event
+				if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+					t.Fatal(err)
+				}
+				if event.Type == EventLog {
+					got = append(got, event.Level)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("log event levels = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
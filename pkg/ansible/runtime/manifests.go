@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 //go:embed manifests/longhorn/*.yaml
@@ -17,35 +18,45 @@ var localPathManifests embed.FS
 //go:embed manifests/scripts/*.sh
 var scriptsManifests embed.FS
 
+// manifestSets lists the embedded manifest sets extracted for the playbooks
+var manifestSets = []struct {
+	name    string
+	embedFS fs.FS
+	srcPath string
+}{
+	{"longhorn", longhornManifests, "manifests/longhorn"},
+	{"local-path", localPathManifests, "manifests/local-path"},
+	{"scripts", scriptsManifests, "manifests/scripts"},
+}
+
 // ExtractManifests extracts embedded manifests to the specified playbook directory
+// and lists the files written for each set. The go:embed patterns already fail the
+// build when a set has no files; an empty file fails here instead of silently
+// deploying a cluster without storage.
 func ExtractManifests(playbookDir string) error {
 	manifestsDir := filepath.Join(playbookDir, "manifests")
 
-	// Extract Longhorn manifests
-	if err := extractFS(longhornManifests, "manifests/longhorn", filepath.Join(manifestsDir, "longhorn")); err != nil {
-		return fmt.Errorf("extract longhorn manifests: %w", err)
-	}
-
-	// Extract local-path manifests
-	if err := extractFS(localPathManifests, "manifests/local-path", filepath.Join(manifestsDir, "local-path")); err != nil {
-		return fmt.Errorf("extract local-path manifests: %w", err)
-	}
-
-	// Extract scripts
-	if err := extractFS(scriptsManifests, "manifests/scripts", filepath.Join(manifestsDir, "scripts")); err != nil {
-		return fmt.Errorf("extract scripts: %w", err)
+	for _, set := range manifestSets {
+		written, err := extractFS(set.embedFS, set.srcPath, filepath.Join(manifestsDir, filepath.Base(set.srcPath)))
+		if err != nil {
+			return fmt.Errorf("extract %s manifests: %w", set.name, err)
+		}
+		// Stderr keeps --export output clean when stdout is redirected
+		fmt.Fprintf(os.Stderr, "Extracted %s manifests: %s\n", set.name, strings.Join(written, ", "))
 	}
 
 	return nil
 }
 
 // extractFS extracts files from an embedded filesystem to the destination directory
-func extractFS(embedFS embed.FS, srcPath, destDir string) error {
+// and returns the relative paths of the files written. Empty files are rejected.
+func extractFS(embedFS fs.FS, srcPath, destDir string) ([]string, error) {
 	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return fmt.Errorf("create dest dir %s: %w", destDir, err)
+		return nil, fmt.Errorf("create dest dir %s: %w", destDir, err)
 	}
 
-	return fs.WalkDir(embedFS, srcPath, func(path string, d fs.DirEntry, err error) error {
+	var written []string
+	err := fs.WalkDir(embedFS, srcPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -56,10 +67,13 @@ func extractFS(embedFS embed.FS, srcPath, destDir string) error {
 		}
 
 		// Read embedded file
-		data, err := embedFS.ReadFile(path)
+		data, err := fs.ReadFile(embedFS, path)
 		if err != nil {
 			return fmt.Errorf("read embedded file %s: %w", path, err)
 		}
+		if len(data) == 0 {
+			return fmt.Errorf("embedded file %s is empty", path)
+		}
 
 		// Calculate relative path and destination
 		relPath, err := filepath.Rel(srcPath, path)
@@ -73,6 +87,9 @@ func extractFS(embedFS embed.FS, srcPath, destDir string) error {
 			return fmt.Errorf("write file %s: %w", destPath, err)
 		}
 
+		written = append(written, relPath)
 		return nil
 	})
+
+	return written, err
 }
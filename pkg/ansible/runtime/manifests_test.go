@@ -0,0 +1,38 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExtractFS(t *testing.T) {
+	src := fstest.MapFS{
+		"manifests/longhorn/longhorn.yaml":       {Data: []byte("kind: Namespace\n")},
+		"manifests/longhorn/storageclass.yaml":   {Data: []byte("kind: StorageClass\n")},
+		"manifests/local-path/local-path.yaml":   {Data: []byte("kind: StorageClass\n")},
+		"manifests/scripts/empty.sh":             {Data: nil},
+		"manifests/scripts/longhorn_validate.sh": {Data: []byte("#!/bin/bash\n")},
+	}
+
+	dest := t.TempDir()
+	written, err := extractFS(src, "manifests/longhorn", dest)
+	if err != nil {
+		t.Fatalf("extractFS() error = %v", err)
+	}
+	want := []string{"longhorn.yaml", "storageclass.yaml"}
+	if !reflect.DeepEqual(written, want) {
+		t.Errorf("extractFS() wrote %v, want %v", written, want)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "storageclass.yaml")); err != nil || string(data) != "kind: StorageClass\n" {
+		t.Errorf("extracted storageclass.yaml = %q, %v", data, err)
+	}
+
+	_, err = extractFS(src, "manifests/scripts", t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "manifests/scripts/empty.sh is empty") {
+		t.Errorf("extractFS() with an empty file: error = %v", err)
+	}
+}
@@ -2,11 +2,13 @@ package runtime
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,9 +18,32 @@ type OutputMode string
 const (
 	OutputVerbose OutputMode = "verbose" // Full Ansible output (current behavior)
 	OutputClean   OutputMode = "clean"   // Emoji-based summary per task
-	OutputJSON    OutputMode = "json"    // Machine-readable JSON output
+	OutputJSON    OutputMode = "json"    // One JSON task result per line (NDJSON)
 )
 
+// TaskResult is the JSON object written per task in OutputJSON mode
+type TaskResult struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Status     TaskStatus `json:"status"`
+	DurationMs int64      `json:"duration_ms"`
+	Message    string     `json:"message,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// jsonStdout is the original stdout while JSON output is enabled
+var jsonStdout *os.File
+
+// EnableJSONOutput reserves stdout for the task results of subsequent
+// OutputJSON playbook runs and sends bloom's own progress messages to stderr,
+// so stdout can be parsed line by line
+func EnableJSONOutput() {
+	if jsonStdout == nil {
+		jsonStdout = os.Stdout
+		os.Stdout = os.Stderr
+	}
+}
+
 // OutputProcessor handles Ansible output processing and formatting
 type OutputProcessor struct {
 	mode         OutputMode
@@ -31,6 +56,32 @@ type OutputProcessor struct {
 	pendingTask  bool
 	config       map[string]string // Configuration values (e.g., CLUSTERFORGE_RELEASE, DOMAIN)
 	joinInfo     string            // Captured join information from Display join information task
+	events       *EventWriter      // Optional structured progress event stream
+	phases       *PhaseTimer       // Optional per-phase time budgets
+	tasks        *PhaseTimer       // Optional per-task time limit
+	dryRun       bool              // Playbook runs in check mode; nothing is applied
+	jsonMu       sync.Mutex
+	jsonTask     taskTracker // Task whose result is pending in JSON mode
+	taskID       int
+	eventMu      sync.Mutex
+	eventStep    string
+	eventStart   time.Time
+	eventDone    bool
+	progress     *progressTracker
+	warnMu       sync.Mutex
+	warnTask     string
+	warnings     []TaskWarning // Non-fatal warnings, repeated in the summary
+	logFormat    string        // LOG_FORMAT of bloom.log: text or json
+	logLevel     string        // LOG_LEVEL: lowest level of log events emitted
+	logMu        sync.Mutex
+	logTask      string
+	summary      summaryRecorder // Task results for the run summary
+}
+
+// TaskWarning is a non-fatal warning reported while a task ran
+type TaskWarning struct {
+	Task    string
+	Message string
 }
 
 // NewOutputProcessor creates a new output processor
@@ -44,6 +95,39 @@ func NewOutputProcessor(mode OutputMode, logFile *os.File, config map[string]str
 	}
 }
 
+// SetEvents attaches a structured event stream that receives progress events
+// alongside the normal output, independent of the output mode
+func (p *OutputProcessor) SetEvents(events *EventWriter) {
+	p.events = events
+}
+
+// SetPhaseTimer attaches a timer that enforces the per-phase time budgets
+func (p *OutputProcessor) SetPhaseTimer(phases *PhaseTimer) {
+	p.phases = phases
+}
+
+// SetTaskTimer attaches a timer that enforces the per-task time limit
+func (p *OutputProcessor) SetTaskTimer(tasks *PhaseTimer) {
+	p.tasks = tasks
+}
+
+// SetLogFormat selects the bloom.log format (LOG_FORMAT); text is the default
+func (p *OutputProcessor) SetLogFormat(format string) {
+	p.logFormat = format
+}
+
+// SetLogLevel sets the lowest level (LOG_LEVEL) of the log events emitted to
+// the events stream. bloom.log is not filtered.
+func (p *OutputProcessor) SetLogLevel(level string) {
+	p.logLevel = level
+}
+
+// SetDryRun marks the run as check mode, so tasks that would run are shown as
+// [dry-run] instead of ok/changed and the summary says nothing was applied
+func (p *OutputProcessor) SetDryRun(dryRun bool) {
+	p.dryRun = dryRun
+}
+
 // ProcessStream reads from input and writes processed output to stdout
 func (p *OutputProcessor) ProcessStream(input io.Reader, output io.Writer) error {
 	scanner := bufio.NewScanner(input)
@@ -52,12 +136,28 @@ func (p *OutputProcessor) ProcessStream(input io.Reader, output io.Writer) error
 		line := scanner.Text()
 
 		// Always write to log file
-		if p.logFile != nil {
-			p.logFile.WriteString(line + "\n")
+		p.writeLog(line)
+		p.summary.observe(line)
+
+		if p.events != nil {
+			p.emitEvent(line)
+		}
+
+		if p.phases != nil {
+			p.phases.Observe(line)
+		}
+		if p.tasks != nil {
+			p.tasks.Observe(line)
 		}
 
 		// Process and write to output based on mode
 		processedLine := p.processLine(line)
+		if warning, ok := p.recordWarning(line); ok && p.mode == OutputClean {
+			if processedLine != "" {
+				processedLine += "\n"
+			}
+			processedLine += "   ⚠️  " + flattenMessage(warning)
+		}
 		if processedLine != "" {
 			if p.pendingTask && !strings.HasPrefix(processedLine, "⏳") {
 				// Erase the ⏳ pending line before printing the result
@@ -77,6 +177,20 @@ func (p *OutputProcessor) ProcessStream(input io.Reader, output io.Writer) error
 	return scanner.Err()
 }
 
+// writeLog appends line to bloom.log in the configured format. stdout and
+// stderr are processed concurrently, hence the lock around the task tracking.
+func (p *OutputProcessor) writeLog(line string) {
+	if p.logFile == nil {
+		return
+	}
+	p.logMu.Lock()
+	defer p.logMu.Unlock()
+	if taskName, ok := ParseTaskHeader(line); ok {
+		p.logTask = taskName
+	}
+	p.logFile.WriteString(formatLogLine(p.logFormat, p.logTask, line) + "\n")
+}
+
 // processLine processes a single line of Ansible output
 func (p *OutputProcessor) processLine(line string) string {
 	// Verbose mode: passthrough everything
@@ -89,8 +203,115 @@ func (p *OutputProcessor) processLine(line string) string {
 		return p.processCleanMode(line)
 	}
 
-	// JSON mode: return raw line (would need more sophisticated handling)
-	return line
+	// JSON mode: one result object per task
+	return p.processJSONMode(line)
+}
+
+// processJSONMode emits one TaskResult per task, once the next header ends
+// it, and suppresses everything else. stdout and stderr are processed
+// concurrently, hence the lock around the task tracking and numbering.
+func (p *OutputProcessor) processJSONMode(line string) string {
+	p.jsonMu.Lock()
+	defer p.jsonMu.Unlock()
+
+	done, ok := p.jsonTask.observe(line)
+	if !ok {
+		return ""
+	}
+	return p.jsonResult(done)
+}
+
+// jsonResult records a finished task in the stats and returns its TaskResult.
+// Callers must hold jsonMu.
+func (p *OutputProcessor) jsonResult(done taskTracker) string {
+	p.taskID++
+	p.stats.Record(done.info.Status)
+
+	result := newTaskResult(p.taskID, done.name, done.info, done.started)
+	data, err := json.Marshal(result)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Flush writes the result of a task still pending when the output ended,
+// which happens when ansible-playbook stops before its PLAY RECAP
+func (p *OutputProcessor) Flush(output io.Writer) {
+	if p.mode != OutputJSON {
+		return
+	}
+	p.jsonMu.Lock()
+	defer p.jsonMu.Unlock()
+	if done := p.jsonTask.finish(); done.info != nil {
+		if line := p.jsonResult(done); line != "" {
+			fmt.Fprintln(output, line)
+		}
+	}
+}
+
+// statusSeverity orders task statuses, so the worst result line of a task
+// decides its status
+var statusSeverity = map[TaskStatus]int{
+	TaskStatusSkipped:     0,
+	TaskStatusOK:          1,
+	TaskStatusChanged:     2,
+	TaskStatusIgnored:     3,
+	TaskStatusFailed:      4,
+	TaskStatusUnreachable: 5,
+}
+
+// taskTracker folds the result lines of the current task into one result.
+// Loops print a line per item before the task's own line, so a task takes the
+// worst status seen and is only finished by the next TASK or PLAY header.
+type taskTracker struct {
+	name    string
+	started time.Time
+	info    *TaskInfo // Worst result so far; nil until the first result line
+}
+
+// observe feeds line to the tracker and returns the task it finishes, if any
+func (t *taskTracker) observe(line string) (taskTracker, bool) {
+	taskName, isHeader := ParseTaskHeader(line)
+	if isHeader || strings.HasPrefix(line, "PLAY [") || strings.HasPrefix(line, "PLAY RECAP") ||
+		strings.HasPrefix(line, "RUNNING HANDLER [") {
+		done := t.finish()
+		if isHeader {
+			t.name = taskName
+			t.started = time.Now()
+		}
+		return done, done.info != nil
+	}
+	if t.name == "" {
+		return taskTracker{}, false
+	}
+
+	// Ansible prints "...ignoring" on the line after the failure it ignores
+	if t.info != nil && t.info.Status == TaskStatusFailed && IsIgnoredError(line) {
+		t.info.Status = TaskStatusIgnored
+		return taskTracker{}, false
+	}
+
+	info, ok := ParseTaskResult(line)
+	if !ok {
+		return taskTracker{}, false
+	}
+	if info.Status == TaskStatusFailed && IsIgnoredError(line) {
+		info.Status = TaskStatusIgnored
+	}
+	// Keep the first line of the worst status: for a failed loop that is the
+	// item's own error rather than "One or more items failed"
+	if t.info == nil || statusSeverity[info.Status] > statusSeverity[t.info.Status] {
+		t.info = info
+	}
+	return taskTracker{}, false
+}
+
+// finish returns the current task and resets the tracker
+func (t *taskTracker) finish() taskTracker {
+	done := *t
+	*t = taskTracker{}
+	return done
 }
 
 // processCleanMode handles clean output formatting
@@ -159,6 +380,112 @@ func (p *OutputProcessor) processCleanMode(line string) string {
 	return ""
 }
 
+// recordWarning collects warnings for the summary and reports whether line
+// carried a new one. Warnings are tracked in every output mode, and stdout and
+// stderr are processed concurrently, hence the lock.
+func (p *OutputProcessor) recordWarning(line string) (string, bool) {
+	p.warnMu.Lock()
+	defer p.warnMu.Unlock()
+
+	if taskName, ok := ParseTaskHeader(line); ok {
+		p.warnTask = taskName
+		return "", false
+	}
+	msg, ok := ParseWarning(line)
+	if !ok {
+		return "", false
+	}
+	for _, w := range p.warnings {
+		if w.Task == p.warnTask && w.Message == msg {
+			return "", false
+		}
+	}
+	p.warnings = append(p.warnings, TaskWarning{Task: p.warnTask, Message: msg})
+	if p.events != nil {
+		p.events.Emit(Event{Type: EventWarning, Step: p.warnTask, Message: msg})
+	}
+	return msg, true
+}
+
+// Warnings returns the warnings collected so far
+func (p *OutputProcessor) Warnings() []TaskWarning {
+	p.warnMu.Lock()
+	defer p.warnMu.Unlock()
+	return append([]TaskWarning(nil), p.warnings...)
+}
+
+// printWarnings lists the collected warnings after the summary, so problems
+// that did not stop the run are not lost in the scrollback
+func (p *OutputProcessor) printWarnings() {
+	warnings := p.Warnings()
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Printf("⚠️  %d warning(s):\n", len(warnings))
+	for _, w := range warnings {
+		if w.Task != "" {
+			fmt.Printf("   [⚠] %s: %s\n", w.Task, flattenMessage(w.Message))
+		} else {
+			fmt.Printf("   [⚠] %s\n", flattenMessage(w.Message))
+		}
+	}
+}
+
+// emitEvent translates a raw Ansible output line into a progress event. Only
+// the first result line of a task produces a completion event, matching the
+// per-task summary shown in clean mode. stdout and stderr are processed
+// concurrently, hence the lock around the step and progress tracking.
+func (p *OutputProcessor) emitEvent(line string) {
+	p.eventMu.Lock()
+	defer p.eventMu.Unlock()
+
+	if p.progress == nil {
+		p.progress = newProgressTracker()
+	}
+	if progress, ok := p.progress.observe(line); ok {
+		p.events.Emit(Event{Type: EventProgress, Progress: &progress})
+	}
+
+	if taskName, ok := ParseTaskHeader(line); ok {
+		p.eventStep = taskName
+		p.eventStart = time.Now()
+		p.eventDone = false
+		p.events.Emit(Event{Type: EventStepStarted, Step: taskName})
+		return
+	}
+
+	if taskInfo, ok := ParseTaskResult(line); ok && p.eventStep != "" {
+		if p.eventDone {
+			return
+		}
+		p.eventDone = true
+
+		if taskInfo.Status == TaskStatusFailed && IsIgnoredError(line) {
+			taskInfo.Status = TaskStatusIgnored
+		}
+
+		eventType := EventStepCompleted
+		if taskInfo.Status == TaskStatusFailed || taskInfo.Status == TaskStatusUnreachable {
+			eventType = EventStepFailed
+		}
+		p.events.Emit(Event{
+			Type:       eventType,
+			Step:       p.eventStep,
+			Status:     taskInfo.Status,
+			Message:    flattenMessage(taskInfo.Message),
+			DurationMs: time.Since(p.eventStart).Milliseconds(),
+		})
+		return
+	}
+
+	if strings.TrimSpace(line) != "" {
+		if level := lineLevel(line); levelEnabled(level, p.logLevel) {
+			p.events.Emit(Event{Type: EventLog, Step: p.eventStep, Message: line, Level: level})
+		}
+	}
+}
+
 var whitespaceRunRegex = regexp.MustCompile(`\s+`)
 
 // flattenMessage collapses a (possibly multi-line) task message into a single
@@ -178,6 +505,15 @@ func flattenMessage(msg string) string {
 
 // getEmoji returns the emoji for a given task status
 func (p *OutputProcessor) getEmoji(status TaskStatus) string {
+	if p.dryRun {
+		switch status {
+		case TaskStatusOK:
+			return "🔎 [dry-run]"
+		case TaskStatusChanged:
+			return "🔎 [dry-run] (would change)"
+		}
+	}
+
 	switch status {
 	case TaskStatusOK:
 		return "✅ (ok)"
@@ -205,8 +541,16 @@ func (p *OutputProcessor) PrintSummary() {
 	duration := time.Since(p.startTime)
 
 	fmt.Println()
+	if p.dryRun {
+		fmt.Printf("Dry run complete: %s\n", p.stats.Summary())
+		fmt.Printf("Total time: %s\n", formatDuration(duration))
+		fmt.Println("🔎 No changes were made. Tasks marked [dry-run] would run; skipped tasks would be skipped.")
+		p.printWarnings()
+		return
+	}
 	fmt.Printf("Playbook complete: %s\n", p.stats.Summary())
 	fmt.Printf("Total time: %s\n", formatDuration(duration))
+	p.printWarnings()
 
 	// Print join information if available
 	if p.joinInfo != "" {
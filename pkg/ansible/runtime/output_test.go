@@ -0,0 +1,193 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestProcessJSONMode(t *testing.T) {
+	output := strings.Join([]string{
+		"PLAY [Cluster Bloom] ****",
+		"TASK [Install packages] ****",
+		"ok: [127.0.0.1] => (item=curl)",
+		"changed: [127.0.0.1] => (item=jq)",
+		"ok: [127.0.0.1]",
+		"TASK [Pull images] ****",
+		"ok: [127.0.0.1] => (item=rke2)",
+		"failed: [127.0.0.1] (item=rocm) => {\"msg\": \"manifest unknown\"}",
+		"fatal: [127.0.0.1]: FAILED! => {\"msg\": \"One or more items failed\"}",
+		"...ignoring",
+		"TASK [Check disks] ****",
+		"ok: [127.0.0.1] => (item=/dev/sdb)",
+		"failed: [127.0.0.1] (item=/dev/sdc) => {\"msg\": \"not empty\"}",
+		"fatal: [127.0.0.1]: FAILED! => {\"msg\": \"One or more items failed\"}",
+		"PLAY RECAP ****",
+		"TASK [Interrupted] ****",
+		"changed: [127.0.0.1]",
+	}, "\n")
+
+	p := NewOutputProcessor(OutputJSON, nil, nil)
+	var out bytes.Buffer
+	if err := p.ProcessStream(strings.NewReader(output), &out); err != nil {
+		t.Fatal(err)
+	}
+	p.Flush(&out)
+
+	// A failed loop keeps the error of the item that failed, not the
+	// "One or more items failed" line that ends it
+	want := []struct {
+		name   string
+		status TaskStatus
+		err    string
+	}{
+		{"Install packages", TaskStatusChanged, ""},
+		{"Pull images", TaskStatusIgnored, "manifest unknown"},
+		{"Check disks", TaskStatusFailed, "not empty"},
+		{"Interrupted", TaskStatusChanged, ""},
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got %d results, want %d:\n%s", len(lines), len(want), out.String())
+	}
+	for i, line := range lines {
+		var result TaskResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatalf("line %d does not decode: %v\n%s", i, err, line)
+		}
+		if result.ID != i+1 || result.Name != want[i].name || result.Status != want[i].status || result.Error != want[i].err {
+			t.Errorf("result %d = %+v, want %+v", i, result, want[i])
+		}
+	}
+	if p.stats.Failed != 1 || p.stats.Ignored != 1 || p.stats.Changed != 2 {
+		t.Errorf("stats = %s", p.stats.Summary())
+	}
+}
+
+// stdout and stderr are processed concurrently; run with -race
+func TestProcessJSONModeConcurrentStreams(t *testing.T) {
+	var stdout, stderr []string
+	for i := 0; i < 50; i++ {
+		stdout = append(stdout, "TASK [Wait for nodes] ****", "ok: [127.0.0.1]")
+		stderr = append(stderr, "[WARNING]: retrying", "fatal: [127.0.0.1]: FAILED! => {\"msg\": \"stray\"}")
+	}
+
+	p := NewOutputProcessor(OutputJSON, nil, nil)
+	var out, errOut bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		p.ProcessStream(strings.NewReader(strings.Join(stderr, "\n")), &errOut)
+		close(done)
+	}()
+	p.ProcessStream(strings.NewReader(strings.Join(stdout, "\n")), &out)
+	<-done
+	p.Flush(&out)
+
+	ids := make(map[int]bool)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()+errOut.String()), "\n") {
+		var result TaskResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatalf("result does not decode: %v\n%s", err, line)
+		}
+		if ids[result.ID] {
+			t.Errorf("duplicate task ID %d", result.ID)
+		}
+		ids[result.ID] = true
+	}
+	if len(ids) != 50 {
+		t.Errorf("got %d results, want 50", len(ids))
+	}
+}
+
+func TestEmitEvent(t *testing.T) {
+	output := strings.Join([]string{
+		"PLAY [Cluster Bloom] ****",
+		"TASK [Phase: preK8s] ****",
+		"ok: [127.0.0.1]",
+		"TASK [Check disks] ****",
+		"skipping: [127.0.0.1]",
+		"TASK [Install packages] ****",
+		"changed: [127.0.0.1] => (item=curl)",
+		"changed: [127.0.0.1] => (item=jq)",
+		"  debug detail",
+		"TASK [Pull images] ****",
+		"fatal: [127.0.0.1]: FAILED! => {\"msg\": \"manifest unknown\"}",
+	}, "\n")
+
+	var events bytes.Buffer
+	p := NewOutputProcessor(OutputClean, nil, nil)
+	p.SetEvents(NewEventWriter(&events))
+	p.SetLogLevel(levelInfo)
+	if err := p.ProcessStream(strings.NewReader(output), &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the first result of a task completes it, and the debug line is
+	// below LOG_LEVEL
+	want := []struct {
+		typ    EventType
+		step   string
+		status TaskStatus
+	}{
+		{EventLog, "", ""},
+		{EventProgress, "", ""},
+		{EventStepStarted, "Phase: preK8s", ""},
+		{EventStepCompleted, "Phase: preK8s", TaskStatusOK},
+		{EventStepStarted, "Check disks", ""},
+		{EventStepCompleted, "Check disks", TaskStatusSkipped},
+		{EventStepStarted, "Install packages", ""},
+		{EventStepCompleted, "Install packages", TaskStatusChanged},
+		{EventStepStarted, "Pull images", ""},
+		{EventStepFailed, "Pull images", TaskStatusFailed},
+	}
+
+	lines := strings.Split(strings.TrimSpace(events.String()), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got %d events, want %d:\n%s", len(lines), len(want), events.String())
+	}
+	for i, line := range lines {
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("event %d does not decode: %v\n%s", i, err, line)
+		}
+		if event.Type != want[i].typ || event.Step != want[i].step || event.Status != want[i].status || event.Time.IsZero() {
+			t.Errorf("event %d = %+v, want %+v", i, event, want[i])
+		}
+	}
+}
+
+// stdout and stderr are processed concurrently; run with -race
+func TestEmitEventConcurrentStreams(t *testing.T) {
+	var stdout, stderr []string
+	for i := 0; i < 50; i++ {
+		stdout = append(stdout, "TASK [Phase: k8s] ****", "ok: [127.0.0.1]")
+		stderr = append(stderr, "[WARNING]: retrying", "ERROR! connection lost")
+	}
+
+	var events bytes.Buffer
+	p := NewOutputProcessor(OutputVerbose, nil, nil)
+	p.SetEvents(NewEventWriter(&events))
+	done := make(chan struct{})
+	go func() {
+		p.ProcessStream(strings.NewReader(strings.Join(stderr, "\n")), &bytes.Buffer{})
+		close(done)
+	}()
+	p.ProcessStream(strings.NewReader(strings.Join(stdout, "\n")), &bytes.Buffer{})
+	<-done
+
+	started := 0
+	for _, line := range strings.Split(strings.TrimSpace(events.String()), "\n") {
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("event does not decode: %v\n%s", err, line)
+		}
+		if event.Type == EventStepStarted {
+			started++
+		}
+	}
+	if started != 50 {
+		t.Errorf("got %d step_started events, want 50", started)
+	}
+}
@@ -142,3 +142,24 @@ func IsIgnoredError(line string) bool {
 	return strings.Contains(line, "...ignoring") ||
 		strings.Contains(line, "ignore_errors=True")
 }
+
+// ParseWarning extracts a warning from an output line: either Ansible's own
+// "[WARNING]: ..." lines or a task "msg" that starts with WARNING or ⚠️, the
+// convention the playbooks use for non-fatal problems
+func ParseWarning(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if rest, ok := strings.CutPrefix(trimmed, "[WARNING]:"); ok {
+		return strings.TrimSpace(rest), true
+	}
+	if !strings.Contains(trimmed, `"msg":`) {
+		return "", false
+	}
+	msg := strings.TrimSpace(extractBriefMessage(trimmed))
+	if strings.HasPrefix(msg, "⚠️") {
+		msg = strings.TrimSpace(strings.TrimPrefix(msg, "⚠️"))
+	} else if !strings.HasPrefix(msg, "WARNING") {
+		return "", false
+	}
+	msg = strings.TrimSpace(strings.TrimPrefix(msg, "WARNING:"))
+	return msg, msg != ""
+}
@@ -0,0 +1,159 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// phaseMarkerPrefix starts the name of the marker task the root playbook runs
+// at the beginning of each deployment phase (e.g. "Phase: k8s")
+const phaseMarkerPrefix = "Phase: "
+
+// phaseTimeoutKeys maps each deployment phase to the config key bounding it
+var phaseTimeoutKeys = map[string]string{
+	"preK8s":  "PREK8S_TIMEOUT",
+	"k8s":     "K8S_TIMEOUT",
+	"postK8s": "POSTK8S_TIMEOUT",
+}
+
+// phaseCategories are the --only-category values for the preK8s, k8s and
+// postK8s phases, in playbook order, with the root playbook tags of each
+// phase (the tags on its "Phase: <name>" marker task)
+var phaseCategories = []struct {
+	name string
+	tags []string
+}{
+	{"pre-k8s", []string{"pre_deployment", "validate_node", "prepare_node", "prep_node"}},
+	{"k8s", []string{"deploy_cluster"}},
+	{"post-k8s", []string{"verify_cluster", "deploy_k8s_apps", "system_pods", "deploy_clusterforge", "dns_check"}},
+}
+
+// CategoryTags returns the --tags value that runs only the tasks of the
+// given deployment category (pre-k8s, k8s or post-k8s)
+func CategoryTags(category string) (string, error) {
+	var names []string
+	for _, c := range phaseCategories {
+		if c.name == category {
+			return strings.Join(c.tags, ","), nil
+		}
+		names = append(names, c.name)
+	}
+	return "", fmt.Errorf("unknown category %q (valid: %s)", category, strings.Join(names, ", "))
+}
+
+// ApplyTags is the --tags value bloom apply runs: the post-Kubernetes task
+// groups that can be re-run against an existing cluster
+const ApplyTags = "verify_cluster,deploy_k8s_apps,deploy_clusterforge"
+
+// PhaseTimer enforces time budgets on spans of the playbook run. It watches
+// the playbook output for the task headers its matcher picks out, each of
+// which starts a new span, and calls onTimeout when the current span runs
+// longer than the limit the matcher gave it.
+type PhaseTimer struct {
+	mu        sync.Mutex
+	match     phaseMatcher
+	timer     *time.Timer
+	expired   string
+	limit     time.Duration
+	onTimeout func(phase string, limit time.Duration)
+}
+
+// phaseMatcher reports whether the task named taskName starts a new span, and
+// that span's name and limit. A zero limit leaves the span unbounded.
+type phaseMatcher func(taskName string) (phase string, limit time.Duration, ok bool)
+
+// NewPhaseTimer reads the phase limits from config. It returns nil when no
+// phase has a limit, so callers can skip phase tracking entirely.
+func NewPhaseTimer(config map[string]string, onTimeout func(phase string, limit time.Duration)) *PhaseTimer {
+	limits := make(map[string]time.Duration)
+	for phase, key := range phaseTimeoutKeys {
+		if d, err := time.ParseDuration(config[key]); err == nil && d > 0 {
+			limits[phase] = d
+		}
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+	return &PhaseTimer{
+		match: func(taskName string) (string, time.Duration, bool) {
+			if !strings.HasPrefix(taskName, phaseMarkerPrefix) {
+				return "", 0, false
+			}
+			phase := strings.TrimSpace(strings.TrimPrefix(taskName, phaseMarkerPrefix))
+			return phase, limits[phase], true
+		},
+		onTimeout: onTimeout,
+	}
+}
+
+// taskTimeoutKey is the config key bounding every individual task
+const taskTimeoutKey = "TASK_TIMEOUT"
+
+// NewTaskTimer reads TASK_TIMEOUT from config and returns a timer that puts
+// that ceiling on each individual task, so a task stuck waiting on a cluster
+// that never becomes ready aborts the run instead of blocking forever. It
+// returns nil when no limit is set, which keeps the unbounded behaviour.
+func NewTaskTimer(config map[string]string, onTimeout func(task string, limit time.Duration)) *PhaseTimer {
+	limit, err := time.ParseDuration(config[taskTimeoutKey])
+	if err != nil || limit <= 0 {
+		return nil
+	}
+	return &PhaseTimer{
+		match: func(taskName string) (string, time.Duration, bool) {
+			return taskName, limit, true
+		},
+		onTimeout: onTimeout,
+	}
+}
+
+// Observe restarts the timer when line is the header of a task that starts a
+// new span. Entering a span without a limit stops the previous span's timer.
+func (t *PhaseTimer) Observe(line string) {
+	taskName, ok := ParseTaskHeader(line)
+	if !ok {
+		return
+	}
+	phase, limit, ok := t.match(taskName)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	if limit <= 0 || t.expired != "" {
+		return
+	}
+	t.timer = time.AfterFunc(limit, func() {
+		t.mu.Lock()
+		t.expired = phase
+		t.limit = limit
+		t.mu.Unlock()
+		t.onTimeout(phase, limit)
+	})
+}
+
+// Stop cancels the timer of the running span
+func (t *PhaseTimer) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// Expired returns the phase or task that exceeded its limit, if any
+func (t *PhaseTimer) Expired() (string, time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.expired == "" {
+		return "", 0, false
+	}
+	return t.expired, t.limit, true
+}
@@ -0,0 +1,261 @@
+package runtime
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestCategoryTags(t *testing.T) {
+	if got, err := CategoryTags("k8s"); err != nil || got != "deploy_cluster" {
+		t.Errorf("CategoryTags(k8s) = %q, %v", got, err)
+	}
+	_, err := CategoryTags("preK8s")
+	if err == nil || !strings.Contains(err.Error(), "valid: pre-k8s, k8s, post-k8s") {
+		t.Errorf("CategoryTags(preK8s) should list the valid categories, got %v", err)
+	}
+}
+
+func TestPhaseTimer(t *testing.T) {
+	tests := []struct {
+		name  string
+		timer func(onTimeout func(string, time.Duration)) *PhaseTimer
+		lines []string
+		want  string // Expired span; empty means none
+	}{
+		{
+			name: "phase over budget",
+			timer: func(onTimeout func(string, time.Duration)) *PhaseTimer {
+				return NewPhaseTimer(map[string]string{"K8S_TIMEOUT": "10ms"}, onTimeout)
+			},
+			lines: []string{"TASK [Phase: k8s] ***", "TASK [Install RKE2] ***"},
+			want:  "k8s",
+		},
+		{
+			name: "unbounded phase stops the timer",
+			timer: func(onTimeout func(string, time.Duration)) *PhaseTimer {
+				return NewPhaseTimer(map[string]string{"K8S_TIMEOUT": "10ms"}, onTimeout)
+			},
+			lines: []string{"TASK [Phase: k8s] ***", "TASK [Phase: postK8s] ***"},
+		},
+		{
+			name: "task over limit",
+			timer: func(onTimeout func(string, time.Duration)) *PhaseTimer {
+				return NewTaskTimer(map[string]string{taskTimeoutKey: "10ms"}, onTimeout)
+			},
+			lines: []string{"TASK [Phase: k8s] ***", "TASK [Wait for nodes] ***"},
+			want:  "Wait for nodes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan struct{}, 1)
+			timer := tt.timer(func(string, time.Duration) { done <- struct{}{} })
+			defer timer.Stop()
+			for _, line := range tt.lines {
+				timer.Observe(line)
+			}
+			select {
+			case <-done:
+			case <-time.After(100 * time.Millisecond):
+			}
+			got, limit, expired := timer.Expired()
+			if got != tt.want || expired != (tt.want != "") {
+				t.Errorf("Expired() = %q, %v, want %q", got, expired, tt.want)
+			}
+			if expired && limit != 10*time.Millisecond {
+				t.Errorf("Expired() limit = %s, want 10ms", limit)
+			}
+		})
+	}
+
+	if NewPhaseTimer(map[string]string{}, nil) != nil || NewTaskTimer(map[string]string{taskTimeoutKey: "0s"}, nil) != nil {
+		t.Error("timers without a limit should be nil")
+	}
+}
+
+// The categories select a phase through the tags of its marker task, so they
+// must match the root playbook
+func TestPhaseCategoriesMatchPlaybook(t *testing.T) {
+	data, err := embeddedPlaybooks.ReadFile("playbooks/cluster-bloom.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plays []struct {
+		Tasks []struct {
+			Name string   `yaml:"name"`
+			Tags []string `yaml:"tags"`
+		} `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &plays); err != nil {
+		t.Fatal(err)
+	}
+
+	var markers [][]string
+	for _, task := range plays[0].Tasks {
+		if strings.HasPrefix(task.Name, phaseMarkerPrefix) {
+			markers = append(markers, task.Tags)
+		}
+	}
+	if len(markers) != len(phaseCategories) {
+		t.Fatalf("playbook has %d phase markers, phaseCategories has %d", len(markers), len(phaseCategories))
+	}
+	for i, c := range phaseCategories {
+		if !reflect.DeepEqual(c.tags, markers[i]) {
+			t.Errorf("%s tags = %v, phase marker has %v", c.name, c.tags, markers[i])
+		}
+	}
+}
+
+// skippedTasks lists the included tasks that --tags tags skips although their
+// include runs, resolving tags the way ansible-playbook does: import_tasks and
+// blocks pass their tags on, include_tasks passes on only those of its apply
+func skippedTasks(t *testing.T, tags string) []string {
+	t.Helper()
+	selected := func(taskTags []string) bool {
+		for _, tag := range taskTags {
+			if tag == "always" || slices.Contains(strings.Split(tags, ","), tag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var skipped []string
+	var walk func(file string, tasks []map[string]any, inherited []string, included bool)
+	load := func(file string, inherited []string, included bool) {
+		data, err := embeddedPlaybooks.ReadFile(path.Join("playbooks", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tasks []map[string]any
+		if err := yaml.Unmarshal(data, &tasks); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		walk(file, tasks, inherited, included)
+	}
+	walk = func(file string, tasks []map[string]any, inherited []string, included bool) {
+		for _, task := range tasks {
+			taskTags := append(slices.Clone(inherited), stringList(task["tags"])...)
+			for _, key := range []string{"block", "rescue", "always"} {
+				if children, ok := task[key].([]any); ok {
+					walk(file, taskList(children), taskTags, included)
+				}
+			}
+			if _, isBlock := task["block"]; isBlock {
+				continue
+			}
+			if !selected(taskTags) {
+				// Only an include's own tasks are meant to run whenever it does
+				if included {
+					skipped = append(skipped, fmt.Sprintf("%s: %v", file, task["name"]))
+				}
+				continue
+			}
+			for key, value := range task {
+				switch strings.TrimPrefix(key, "ansible.builtin.") {
+				case "import_tasks":
+					load(path.Join(path.Dir(file), value.(string)), taskTags, included)
+				case "include_tasks":
+					target, _ := value.(string)
+					var applied []string
+					if args, ok := value.(map[string]any); ok {
+						target, _ = args["file"].(string)
+						if apply, ok := args["apply"].(map[string]any); ok {
+							applied = stringList(apply["tags"])
+						}
+					}
+					load(path.Join(path.Dir(file), target), applied, true)
+				}
+			}
+		}
+	}
+
+	data, err := embeddedPlaybooks.ReadFile("playbooks/cluster-bloom.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plays []map[string]any
+	if err := yaml.Unmarshal(data, &plays); err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ := plays[0]["tasks"].([]any)
+	walk("cluster-bloom.yaml", taskList(tasks), nil, false)
+	return skipped
+}
+
+func taskList(items []any) []map[string]any {
+	var tasks []map[string]any
+	for _, item := range items {
+		if task, ok := item.(map[string]any); ok {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+func stringList(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var list []string
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list
+	}
+	return nil
+}
+
+// bloom apply must run the whole of every task file its phases include
+func TestApplyTagsRunIncludedTasks(t *testing.T) {
+	for _, task := range skippedTasks(t, ApplyTags) {
+		t.Errorf("--tags %s skips %s", ApplyTags, task)
+	}
+}
+
+// Each category, and each single tag of the playbooks, must run the whole of
+// every task file it includes
+func TestTagsRunIncludedTasks(t *testing.T) {
+	var tagSets []string
+	for _, c := range phaseCategories {
+		tagSets = append(tagSets, strings.Join(c.tags, ","))
+	}
+	tagList := regexp.MustCompile(`tags: \[([^\]]*)\]`)
+	err := fs.WalkDir(embeddedPlaybooks, "playbooks", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := embeddedPlaybooks.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range tagList.FindAllStringSubmatch(string(data), -1) {
+			for _, tag := range strings.Split(match[1], ",") {
+				if tag = strings.TrimSpace(tag); !slices.Contains(tagSets, tag) {
+					tagSets = append(tagSets, tag)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tags := range tagSets {
+		for _, task := range skippedTasks(t, tags) {
+			t.Errorf("--tags %s skips %s", tags, task)
+		}
+	}
+}
@@ -13,6 +13,15 @@ import (
 //go:embed playbooks
 var embeddedPlaybooks embed.FS
 
+// reuseSSHKey lets a run pick up the ephemeral SSH key of an interrupted one
+var reuseSSHKey bool
+
+// SetReuseSSHKey makes subsequent playbook runs reuse, or replace, a bloom SSH
+// key that an interrupted run left in authorized_keys instead of failing
+func SetReuseSSHKey(reuse bool) {
+	reuseSSHKey = reuse
+}
+
 func getWorkDir() (string, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
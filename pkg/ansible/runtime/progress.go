@@ -0,0 +1,84 @@
+package runtime
+
+import (
+	"strings"
+	"time"
+)
+
+// deploymentPhases are the phases of a full deployment, in playbook order
+var deploymentPhases = []string{"preK8s", "k8s", "postK8s"}
+
+// Progress is the overall position of a deployment, carried by progress
+// events. ETASeconds is -1 until a phase has completed in this run.
+type Progress struct {
+	Completed      int    `json:"completed"`
+	Total          int    `json:"total"`
+	Percent        int    `json:"percent"`
+	Phase          string `json:"phase,omitempty"`
+	ElapsedSeconds int64  `json:"elapsed_seconds"`
+	ETASeconds     int64  `json:"eta_seconds"`
+}
+
+// progressTracker derives Progress from the phase marker tasks. The playbook
+// has no fixed task count (includes are conditional), so the phases are the
+// unit of progress and the ETA is the average duration of the phases that
+// completed in this run times the phases left. It is not safe for concurrent
+// use; OutputProcessor.emitEvent calls it under eventMu.
+type progressTracker struct {
+	start      time.Time
+	now        func() time.Time
+	firstPhase int // Index of the first phase seen; earlier ones were skipped (--resume, --tags)
+	completed  int
+	phase      string
+}
+
+func newProgressTracker() *progressTracker {
+	return &progressTracker{start: time.Now(), now: time.Now, firstPhase: -1}
+}
+
+// observe returns the progress to report for line, if it starts a phase or
+// is the recap of a successful run
+func (t *progressTracker) observe(line string) (Progress, bool) {
+	if taskName, ok := ParseTaskHeader(line); ok && strings.HasPrefix(taskName, phaseMarkerPrefix) {
+		phase := strings.TrimSpace(strings.TrimPrefix(taskName, phaseMarkerPrefix))
+		index := -1
+		for i, p := range deploymentPhases {
+			if p == phase {
+				index = i
+			}
+		}
+		if index < 0 {
+			return Progress{}, false
+		}
+		if t.firstPhase < 0 {
+			t.firstPhase = index
+		}
+		t.completed = index
+		t.phase = phase
+		return t.progress(), true
+	}
+	if strings.Contains(line, " failed=0 ") && strings.Contains(line, " unreachable=0 ") && t.firstPhase >= 0 {
+		t.completed = len(deploymentPhases)
+		t.phase = ""
+		return t.progress(), true
+	}
+	return Progress{}, false
+}
+
+func (t *progressTracker) progress() Progress {
+	elapsed := t.now().Sub(t.start)
+	total := len(deploymentPhases)
+	p := Progress{
+		Completed:      t.completed,
+		Total:          total,
+		Percent:        t.completed * 100 / total,
+		Phase:          t.phase,
+		ElapsedSeconds: int64(elapsed.Seconds()),
+		ETASeconds:     -1,
+	}
+	if done := t.completed - t.firstPhase; done > 0 {
+		average := elapsed / time.Duration(done)
+		p.ETASeconds = int64((average * time.Duration(total-t.completed)).Seconds())
+	}
+	return p
+}
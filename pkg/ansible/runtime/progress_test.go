@@ -0,0 +1,67 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgressTracker(t *testing.T) {
+	recap := "localhost : ok=42 changed=7 unreachable=0 failed=0 skipped=3 rescued=0 ignored=0"
+
+	type step struct {
+		after time.Duration // Time since the tracker started
+		line  string
+		want  *Progress // nil means no progress is reported
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "full deployment",
+			steps: []step{
+				{0, "TASK [Phase: preK8s] ***", &Progress{Completed: 0, Total: 3, Percent: 0, Phase: "preK8s", ETASeconds: -1}},
+				{time.Minute, "TASK [Install packages] ***", nil},
+				{10 * time.Minute, "TASK [Phase: k8s] ***", &Progress{Completed: 1, Total: 3, Percent: 33, Phase: "k8s", ElapsedSeconds: 600, ETASeconds: 1200}},
+				{20 * time.Minute, "TASK [Phase: postK8s] ***", &Progress{Completed: 2, Total: 3, Percent: 66, Phase: "postK8s", ElapsedSeconds: 1200, ETASeconds: 600}},
+				{24 * time.Minute, recap, &Progress{Completed: 3, Total: 3, Percent: 100, ElapsedSeconds: 1440, ETASeconds: 0}},
+			},
+		},
+		{
+			name: "resumed at k8s",
+			steps: []step{
+				{0, "TASK [Phase: k8s] ***", &Progress{Completed: 1, Total: 3, Percent: 33, Phase: "k8s", ETASeconds: -1}},
+				{15 * time.Minute, "TASK [Phase: postK8s] ***", &Progress{Completed: 2, Total: 3, Percent: 66, Phase: "postK8s", ElapsedSeconds: 900, ETASeconds: 900}},
+				{20 * time.Minute, recap, &Progress{Completed: 3, Total: 3, Percent: 100, ElapsedSeconds: 1200, ETASeconds: 0}},
+			},
+		},
+		{
+			name: "unknown phase and recap without phases",
+			steps: []step{
+				{0, "TASK [Phase: cleanup] ***", nil},
+				{time.Minute, recap, nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+			now := start
+			tracker := &progressTracker{start: start, now: func() time.Time { return now }, firstPhase: -1}
+			for _, s := range tt.steps {
+				now = start.Add(s.after)
+				got, ok := tracker.observe(s.line)
+				if s.want == nil {
+					if ok {
+						t.Errorf("observe(%q) = %+v, want no progress", s.line, got)
+					}
+					continue
+				}
+				if !ok || got != *s.want {
+					t.Errorf("observe(%q) = %+v, %v, want %+v", s.line, got, ok, *s.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package runtime
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resumeKeysPrefix starts the bloom.log line recording the resumeKeys values
+// of a run, so a later --resume can tell whether the config changed
+const resumeKeysPrefix = "# bloom resume keys: "
+
+// resumeKeys are the config keys that shape node preparation and the RKE2
+// install. Skipping those phases is only safe while none of them changed.
+var resumeKeys = []string{
+	"FIRST_NODE", "CONTROL_PLANE", "GPU_NODE", "SERVER_IP", "SERVER_IPS", "CLUSTER_LISTEN_IP",
+	"NO_DISKS_FOR_CLUSTER", "STORAGE_BACKEND", "CLUSTER_DISKS", "CLUSTER_PREMOUNTED_DISKS", "RANCHER_DISK",
+	"RKE2_VERSION", "RKE2_DATA_DIR", "RKE2_CNI", "RKE2_IP_FAMILY", "RKE2_CLUSTER_CIDR", "RKE2_SERVICE_CIDR", "CNI_MTU",
+	"RKE2_EXTRA_CONFIG", "NODE_LABELS", "NODE_TAINTS", "DOMAIN", "AIRGAP", "RKE2_ARTIFACT_PATH",
+}
+
+// resumablePhases are the phases a resumed run can skip, in playbook order.
+// postK8s only re-applies manifests and always runs.
+var resumablePhases = []string{"preK8s", "k8s"}
+
+// ResumeKeys returns the resumeKeys values of config. Pass the result to the
+// playbook as bloom_resume_keys so the run records them in bloom.log.
+func ResumeKeys(config map[string]any) map[string]any {
+	keys := make(map[string]any)
+	for _, key := range resumeKeys {
+		if value, ok := config[key]; ok {
+			keys[key] = value
+		}
+	}
+	return keys
+}
+
+// resumeKeysLine returns the bloom.log line for the bloom_resume_keys extra
+// var, or "" when the run was not started with one
+func resumeKeysLine(extraArgs []string) string {
+	for i := 0; i+1 < len(extraArgs); i++ {
+		if extraArgs[i] != "-e" {
+			continue
+		}
+		var varMap map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(extraArgs[i+1]), &varMap); err != nil {
+			continue
+		}
+		if keys, ok := varMap["bloom_resume_keys"]; ok {
+			return resumeKeysPrefix + string(keys)
+		}
+	}
+	return ""
+}
+
+// ResumePlan describes what a --resume run skips
+type ResumePlan struct {
+	FailedPhase string   // Phase the previous run stopped in
+	Skip        []string // Completed phases to skip, in playbook order
+}
+
+// PlanResume reads bloom.log in the current directory and returns the phases
+// the previous run completed. It refuses when that run succeeded, predates
+// resume support, or used different values for any of resumeKeys.
+func PlanResume(config map[string]any) (ResumePlan, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ResumePlan{}, fmt.Errorf("get current directory: %w", err)
+	}
+	f, err := os.Open(filepath.Join(cwd, "bloom.log"))
+	if err != nil {
+		return ResumePlan{}, fmt.Errorf("no bloom.log from a previous run to resume from: %w", err)
+	}
+	defer f.Close()
+	return planResume(f, config)
+}
+
+// planResume plans the resume from the last run in log. Runs append to
+// bloom.log, so each checkpoint line starts the state of a new run.
+func planResume(log io.Reader, config map[string]any) (ResumePlan, error) {
+	var recorded map[string]any
+	var lastPhase string
+	succeeded := false
+	scanner := bufio.NewScanner(log)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line, _ := parseLogLine(scanner.Text())
+		if strings.HasPrefix(line, resumeKeysPrefix) {
+			recorded = nil
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, resumeKeysPrefix)), &recorded); err != nil {
+				return ResumePlan{}, fmt.Errorf("parse resume keys in bloom.log: %w", err)
+			}
+			lastPhase = ""
+			succeeded = false
+		} else if name, ok := ParseTaskHeader(line); ok && strings.HasPrefix(name, phaseMarkerPrefix) {
+			lastPhase = strings.TrimPrefix(name, phaseMarkerPrefix)
+		} else if strings.Contains(line, " failed=0 ") && strings.Contains(line, " unreachable=0 ") {
+			succeeded = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ResumePlan{}, fmt.Errorf("read bloom.log: %w", err)
+	}
+
+	if succeeded {
+		return ResumePlan{}, fmt.Errorf("the previous run in bloom.log completed successfully; there is nothing to resume")
+	}
+	if recorded == nil {
+		return ResumePlan{}, fmt.Errorf("bloom.log has no resume checkpoint (written by an older bloom or a run with --tags); rerun without --resume")
+	}
+	if changed := changedResumeKeys(recorded, ResumeKeys(config)); len(changed) > 0 {
+		return ResumePlan{}, fmt.Errorf("%s changed since the run in bloom.log, so its completed phases may no longer match the config; rerun without --resume", strings.Join(changed, ", "))
+	}
+
+	plan := ResumePlan{FailedPhase: lastPhase}
+	for _, phase := range resumablePhases {
+		if phase == lastPhase {
+			break
+		}
+		if lastPhase != "" {
+			plan.Skip = append(plan.Skip, phase)
+		}
+	}
+	return plan, nil
+}
+
+// changedResumeKeys compares values through their JSON encoding, so numbers
+// and lists decoded from bloom.log match the values loaded from bloom.yaml
+func changedResumeKeys(recorded, current map[string]any) []string {
+	var changed []string
+	for _, key := range resumeKeys {
+		before, _ := json.Marshal(recorded[key])
+		var normalized any
+		if raw, err := json.Marshal(current[key]); err == nil {
+			json.Unmarshal(raw, &normalized)
+		}
+		after, _ := json.Marshal(normalized)
+		if string(before) != string(after) {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,104 @@
+package runtime
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPlanResume(t *testing.T) {
+	config := map[string]any{"FIRST_NODE": true, "CLUSTER_DISKS": "/dev/sdb", "RKE2_VERSION": "v1.31.4+rke2r1"}
+	checkpoint := resumeKeysPrefix + `{"CLUSTER_DISKS":"/dev/sdb","FIRST_NODE":true,"RKE2_VERSION":"v1.31.4+rke2r1"}`
+	recap := func(failed string) string {
+		return "localhost : ok=42 changed=7 unreachable=0 failed=" + failed + " skipped=3 rescued=0 ignored=0 "
+	}
+
+	tests := []struct {
+		name    string
+		log     []string
+		want    ResumePlan
+		wantErr string // Substring of the error; empty means no error
+	}{
+		{
+			name: "failed in k8s",
+			log:  []string{checkpoint, "TASK [Phase: preK8s] ***", "TASK [Phase: k8s] ***", recap("1")},
+			want: ResumePlan{FailedPhase: "k8s", Skip: []string{"preK8s"}},
+		},
+		{
+			name: "failed in postK8s",
+			log:  []string{checkpoint, "TASK [Phase: preK8s] ***", "TASK [Phase: k8s] ***", "TASK [Phase: postK8s] ***", recap("1")},
+			want: ResumePlan{FailedPhase: "postK8s", Skip: []string{"preK8s", "k8s"}},
+		},
+		{
+			name: "failed run after a successful one",
+			log: []string{
+				checkpoint, "TASK [Phase: preK8s] ***", "TASK [Phase: k8s] ***", "TASK [Phase: postK8s] ***", recap("0"),
+				checkpoint, "TASK [Phase: preK8s] ***", recap("1"),
+			},
+			want: ResumePlan{FailedPhase: "preK8s"},
+		},
+		{
+			name: "last run failed before its first phase",
+			log: []string{
+				checkpoint, "TASK [Phase: preK8s] ***", "TASK [Phase: k8s] ***", recap("1"),
+				checkpoint, "TASK [Gathering Facts] ***", recap("1"),
+			},
+			want: ResumePlan{},
+		},
+		{
+			name: "JSON log lines",
+			log: []string{
+				`{"time":"2026-10-16T10:00:00Z","level":"info","msg":` + strconv.Quote(checkpoint) + `}`,
+				`{"time":"2026-10-16T10:00:01Z","level":"info","msg":"TASK [Phase: k8s] ***"}`,
+			},
+			want: ResumePlan{FailedPhase: "k8s", Skip: []string{"preK8s"}},
+		},
+		{
+			name:    "successful last run",
+			log:     []string{checkpoint, "TASK [Phase: k8s] ***", recap("1"), checkpoint, "TASK [Phase: postK8s] ***", recap("0")},
+			wantErr: "completed successfully",
+		},
+		{
+			name:    "no checkpoint",
+			log:     []string{"TASK [Phase: k8s] ***", recap("1")},
+			wantErr: "no resume checkpoint",
+		},
+		{
+			name:    "changed key",
+			log:     []string{strings.Replace(checkpoint, "/dev/sdb", "/dev/sdc", 1), "TASK [Phase: k8s] ***", recap("1")},
+			wantErr: "CLUSTER_DISKS changed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := planResume(strings.NewReader(strings.Join(tt.log, "\n")+"\n"), config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("planResume() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("planResume() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planResume() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedResumeKeys(t *testing.T) {
+	// Recorded values come back from JSON: numbers as float64, lists as []any
+	recorded := map[string]any{"FIRST_NODE": true, "CNI_MTU": float64(1450), "NODE_LABELS": []any{"gpu=true"}}
+
+	if changed := changedResumeKeys(recorded, map[string]any{"FIRST_NODE": true, "CNI_MTU": 1450, "NODE_LABELS": []string{"gpu=true"}}); len(changed) != 0 {
+		t.Errorf("changedResumeKeys() = %v for equal values", changed)
+	}
+	got := changedResumeKeys(recorded, map[string]any{"FIRST_NODE": false, "CNI_MTU": 1450, "NODE_LABELS": []string{"gpu=true"}, "DOMAIN": "example.com"})
+	if want := []string{"FIRST_NODE", "DOMAIN"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changedResumeKeys() = %v, want %v", got, want)
+	}
+}
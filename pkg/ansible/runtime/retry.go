@@ -0,0 +1,55 @@
+package runtime
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Marker tasks at the start and end of validate_node/main.yaml. A run that
+// logged the first but not the second failed on an OS or system requirement.
+const (
+	validationStartedTask = "TASK [Node validation: started]"
+	validationPassedTask  = "TASK [Node validation: passed]"
+)
+
+// RetryableFailure reports whether a failed deployment is worth re-running
+// unchanged. Interrupted runs and node validation failures (unsupported OS,
+// missing resources, unprivileged container, ROCm mismatch) are not retried,
+// since they fail the same way until the node or config is fixed. The reason
+// is returned for runs that should not be retried.
+func RetryableFailure(exitCode int) (bool, string) {
+	switch exitCode {
+	case 0:
+		return false, "the run succeeded"
+	case 129, 130, 131, 143:
+		return false, "the run was interrupted"
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return true, ""
+	}
+	f, err := os.Open(filepath.Join(cwd, "bloom.log"))
+	if err != nil {
+		return true, ""
+	}
+	defer f.Close()
+
+	started, passed := false, false
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line, _ := parseLogLine(scanner.Text())
+		if strings.HasPrefix(line, validationStartedTask) {
+			started = true
+		} else if strings.HasPrefix(line, validationPassedTask) {
+			passed = true
+		}
+	}
+	if started && !passed {
+		return false, "node validation failed (OS or system requirement); fix the node or config and rerun"
+	}
+	return true, ""
+}
@@ -0,0 +1,52 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultRKE2DataDir is where RKE2 keeps its binaries, images, etcd and
+// manifests unless RKE2_DATA_DIR sets data-dir in its config.yaml
+const DefaultRKE2DataDir = "/var/lib/rancher/rke2"
+
+// RKE2DataDir returns the data-dir of the installed RKE2, read from its
+// config.yaml, or DefaultRKE2DataDir when none is set
+func RKE2DataDir() string {
+	data, err := os.ReadFile(rke2ConfigPath)
+	if err != nil {
+		return DefaultRKE2DataDir
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) != "data-dir" {
+			continue
+		}
+		if value = strings.Trim(strings.TrimSpace(value), `"'`); filepath.IsAbs(value) {
+			return filepath.Clean(value)
+		}
+	}
+	return DefaultRKE2DataDir
+}
+
+// rke2DataDirEntries are what RKE2 creates in its data-dir
+var rke2DataDirEntries = []string{"agent", "bin", "data", "server"}
+
+// rke2DataDirRemovals returns what cleanup deletes for dataDir: all of the
+// default data-dir, but only the RKE2-owned entries of a relocated one, so a
+// data-dir that shares its parent with other data never takes that data along
+func rke2DataDirRemovals(dataDir string) []string {
+	if dataDir == DefaultRKE2DataDir {
+		return []string{dataDir}
+	}
+	removals := make([]string, 0, len(rke2DataDirEntries))
+	for _, entry := range rke2DataDirEntries {
+		removals = append(removals, filepath.Join(dataDir, entry))
+	}
+	return removals
+}
+
+// rke2Kubectl returns the path of the kubectl binary RKE2 installs
+func rke2Kubectl() string {
+	return filepath.Join(RKE2DataDir(), "bin", "kubectl")
+}
@@ -0,0 +1,50 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRKE2DataDir(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string // Empty means no config.yaml
+		want   string
+	}{
+		{name: "no config", want: DefaultRKE2DataDir},
+		{name: "not set", config: "cni: cilium\nnode-ip: 10.0.0.1\n", want: DefaultRKE2DataDir},
+		{name: "quoted", config: "cni: cilium\ndata-dir: \"/data/rke2\"\n", want: "/data/rke2"},
+		{name: "trailing slash", config: "data-dir: /data/rke2/\n", want: "/data/rke2"},
+		{name: "relative ignored", config: "data-dir: data/rke2\n", want: DefaultRKE2DataDir},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if tt.config != "" {
+				if err := os.WriteFile(path, []byte(tt.config), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			origConfig := rke2ConfigPath
+			rke2ConfigPath = path
+			defer func() { rke2ConfigPath = origConfig }()
+
+			if got := RKE2DataDir(); got != tt.want {
+				t.Errorf("RKE2DataDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRKE2DataDirRemovals(t *testing.T) {
+	if got, want := rke2DataDirRemovals(DefaultRKE2DataDir), []string{DefaultRKE2DataDir}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rke2DataDirRemovals(default) = %q, want %q", got, want)
+	}
+	want := []string{"/data/rke2/agent", "/data/rke2/bin", "/data/rke2/data", "/data/rke2/server"}
+	if got := rke2DataDirRemovals("/data/rke2"); !reflect.DeepEqual(got, want) {
+		t.Errorf("rke2DataDirRemovals(relocated) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SummaryFileName is the run summary written next to bloom.log at the end of
+// every playbook run
+const SummaryFileName = "bloom-summary.json"
+
+// RunSummary is the machine-readable record of a playbook run, for automation
+// that needs to check what happened without parsing bloom.log
+type RunSummary struct {
+	Playbook   string       `json:"playbook"`
+	DryRun     bool         `json:"dry_run"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at"`
+	DurationMs int64        `json:"duration_ms"`
+	Success    bool         `json:"success"`
+	ExitCode   int          `json:"exit_code"`
+	Tasks      []TaskResult `json:"tasks"`
+}
+
+// newTaskResult builds the result of a task from its worst result line, with
+// the error message for failed, unreachable and ignored tasks
+func newTaskResult(id int, name string, info *TaskInfo, started time.Time) TaskResult {
+	result := TaskResult{
+		ID:         id,
+		Name:       name,
+		Status:     info.Status,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	switch info.Status {
+	case TaskStatusFailed, TaskStatusUnreachable, TaskStatusIgnored:
+		result.Error = flattenMessage(info.Message)
+	default:
+		result.Message = flattenMessage(info.Message)
+	}
+	return result
+}
+
+// summaryRecorder collects one TaskResult per task, whatever the output mode.
+// stdout and stderr are processed concurrently, hence the lock.
+type summaryRecorder struct {
+	mu      sync.Mutex
+	current taskTracker
+	results []TaskResult
+}
+
+// observe records the result of a task once the next header ends it
+func (r *summaryRecorder) observe(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if done, ok := r.current.observe(line); ok {
+		r.record(done)
+	}
+}
+
+// record appends the result of a finished task
+func (r *summaryRecorder) record(done taskTracker) {
+	r.results = append(r.results, newTaskResult(len(r.results)+1, done.name, done.info, done.started))
+}
+
+// tasks returns a copy of the recorded task results, including the task
+// still pending if the output ended before the PLAY RECAP
+func (r *summaryRecorder) tasks() []TaskResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if done := r.current.finish(); done.info != nil {
+		r.record(done)
+	}
+	return append([]TaskResult{}, r.results...)
+}
+
+// WriteRunSummary writes the run summary for playbook, which exited with
+// exitCode, to path. Written to a temporary file first, so readers never see
+// a partial summary.
+func (p *OutputProcessor) WriteRunSummary(path, playbook string, exitCode int) error {
+	finished := time.Now()
+	summary := RunSummary{
+		Playbook:   playbook,
+		DryRun:     p.dryRun,
+		StartedAt:  p.startTime.UTC(),
+		FinishedAt: finished.UTC(),
+		DurationMs: finished.Sub(p.startTime).Milliseconds(),
+		Success:    exitCode == 0,
+		ExitCode:   exitCode,
+		Tasks:      p.summary.tasks(),
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode run summary: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+SummaryFileName+"-*")
+	if err != nil {
+		return fmt.Errorf("write run summary: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write run summary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write run summary: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("write run summary: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write run summary: %w", err)
+	}
+	return nil
+}
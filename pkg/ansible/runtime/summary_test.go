@@ -0,0 +1,80 @@
+package runtime
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteRunSummary(t *testing.T) {
+	output := strings.Join([]string{
+		"PLAY [Cluster Bloom] ****",
+		"TASK [Install packages] ****",
+		"changed: [127.0.0.1] => {\"changed\": true}",
+		"TASK [Check optional tool] ****",
+		"fatal: [127.0.0.1]: FAILED! => {\"msg\": \"not found\"}",
+		"...ignoring",
+		"TASK [Pull images] ****",
+		"ok: [127.0.0.1] => (item=rke2)",
+		"failed: [127.0.0.1] (item=rocm) => {\"msg\": \"manifest unknown\"}",
+		"fatal: [127.0.0.1]: FAILED! => {\"msg\": \"One or more items failed\"}",
+		"TASK [Skip GPU setup] ****",
+		"skipping: [127.0.0.1]",
+		"TASK [Install RKE2] ****",
+		"fatal: [127.0.0.1]: FAILED! => {\"msg\": \"download failed\"}",
+		"PLAY RECAP ****",
+	}, "\n")
+
+	for _, mode := range []OutputMode{OutputClean, OutputVerbose, OutputJSON} {
+		t.Run(string(mode), func(t *testing.T) {
+			p := NewOutputProcessor(mode, nil, nil)
+			if err := p.ProcessStream(strings.NewReader(output), io.Discard); err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join(t.TempDir(), SummaryFileName)
+			if err := p.WriteRunSummary(path, "cluster-bloom.yaml", 2); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var summary RunSummary
+			if err := json.Unmarshal(data, &summary); err != nil {
+				t.Fatalf("summary does not decode: %v\n%s", err, data)
+			}
+
+			if summary.Success || summary.ExitCode != 2 || summary.Playbook != "cluster-bloom.yaml" {
+				t.Errorf("summary = success %v, exit %d, playbook %q", summary.Success, summary.ExitCode, summary.Playbook)
+			}
+			if summary.FinishedAt.Before(summary.StartedAt) {
+				t.Errorf("finished_at %v is before started_at %v", summary.FinishedAt, summary.StartedAt)
+			}
+
+			want := []struct {
+				name   string
+				status TaskStatus
+				err    string
+			}{
+				{"Install packages", TaskStatusChanged, ""},
+				{"Check optional tool", TaskStatusIgnored, "not found"},
+				{"Pull images", TaskStatusFailed, "manifest unknown"},
+				{"Skip GPU setup", TaskStatusSkipped, ""},
+				{"Install RKE2", TaskStatusFailed, "download failed"},
+			}
+			if len(summary.Tasks) != len(want) {
+				t.Fatalf("got %d tasks, want %d: %+v", len(summary.Tasks), len(want), summary.Tasks)
+			}
+			for i, w := range want {
+				got := summary.Tasks[i]
+				if got.ID != i+1 || got.Name != w.name || got.Status != w.status || !strings.Contains(got.Error, w.err) {
+					t.Errorf("task %d = %+v, want %s %s %q", i, got, w.name, w.status, w.err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+//go:build linux
+
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+)
+
+// ManagedDisk is a bloom-managed CLUSTER_DISKS fstab entry
+type ManagedDisk struct {
+	Device     string
+	MountPoint string
+	Mapper     string // LUKS mapping holding the filesystem (ENCRYPT_DISKS); Device is then the disk backing it
+}
+
+// isManagedClusterDiskLine reports whether an fstab line belongs to a CLUSTER_DISKS
+// mount. Premounted disks and RANCHER_DISK carry their own markers and are excluded.
+func isManagedClusterDiskLine(line string) bool {
+	return strings.Contains(line, "# managed by cluster-bloom") &&
+		!strings.Contains(line, "# premounted by cluster-bloom") &&
+		!strings.Contains(line, "# managed by cluster-bloom rancher-disk")
+}
+
+// ListManagedClusterDisks returns the CLUSTER_DISKS entries currently in /etc/fstab
+func ListManagedClusterDisks() ([]ManagedDisk, error) {
+	data, err := os.ReadFile("/etc/fstab")
+	if err != nil {
+		return nil, fmt.Errorf("read fstab: %w", err)
+	}
+
+	crypttab, err := os.ReadFile("/etc/crypttab")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read crypttab: %w", err)
+	}
+
+	var entries []ManagedDisk
+	for _, line := range strings.Split(string(data), "\n") {
+		if !isManagedClusterDiskLine(line) {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		entry := ManagedDisk{
+			Device:     extractDeviceFromFstabLine(line),
+			MountPoint: fields[1],
+		}
+		// With ENCRYPT_DISKS the fstab UUID is that of the ext4 inside the
+		// LUKS container, so map it back to the disk CLUSTER_DISKS names
+		if mapper := luksMapperFor(entry.MountPoint, entry.Device, string(crypttab)); mapper != "" {
+			entry.Mapper = mapper
+			entry.Device = luksBackingDevice(mapper, string(crypttab))
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// luksMapperFor returns the bloom LUKS mapping a CLUSTER_DISKS mount lives on,
+// or "" for an unencrypted disk. storage.yaml mounts /dev/mapper/bloom-diskN at
+// /mnt/diskN, so a closed mapping is still found through its crypttab entry.
+func luksMapperFor(mountPoint, device, crypttab string) string {
+	if name, ok := strings.CutPrefix(device, "/dev/mapper/"); ok && strings.HasPrefix(name, luksMapperPrefix) {
+		return name
+	}
+	index, ok := strings.CutPrefix(mountPoint, "/mnt/disk")
+	if !ok || index == "" || strings.Trim(index, "0123456789") != "" {
+		return ""
+	}
+	if name := luksMapperPrefix + index; crypttabSource(crypttab, name) != "" {
+		return name
+	}
+	return ""
+}
+
+// crypttabSource returns the source device field (e.g. UUID=...) of the
+// crypttab entry for mapping name
+func crypttabSource(crypttab, name string) string {
+	for _, line := range strings.Split(crypttab, "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == name {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// luksBackingDevice returns the disk behind a bloom LUKS mapping: from
+// cryptsetup status while it is open, otherwise from its crypttab entry
+func luksBackingDevice(mapper, crypttab string) string {
+	if out, err := exec.Command("cryptsetup", "status", mapper).Output(); err == nil {
+		if device := parseCryptsetupStatusDevice(string(out)); device != "" {
+			return device
+		}
+	}
+	source := crypttabSource(crypttab, mapper)
+	if uuid, ok := strings.CutPrefix(source, "UUID="); ok {
+		return resolveUUIDToDevice(uuid)
+	}
+	if strings.HasPrefix(source, "/dev/") {
+		return source
+	}
+	return ""
+}
+
+// parseCryptsetupStatusDevice extracts the backing device from the output of
+// cryptsetup status ("  device:  /dev/sdb")
+func parseCryptsetupStatusDevice(out string) string {
+	for _, line := range strings.Split(out, "\n") {
+		if device, ok := strings.CutPrefix(strings.TrimSpace(line), "device:"); ok {
+			return strings.TrimSpace(device)
+		}
+	}
+	return ""
+}
+
+// removeCrypttabEntries drops the entries of the given mappings from crypttab
+// and returns the remaining content and the removed lines
+func removeCrypttabEntries(crypttab string, mappers map[string]bool) (string, []string) {
+	var kept, removed []string
+	for _, line := range strings.Split(crypttab, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && mappers[fields[0]] {
+			removed = append(removed, strings.TrimSpace(line))
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n"), removed
+}
+
+// PrintDiskTeardownPreview lists what TeardownBloomDisks would touch
+func PrintDiskTeardownPreview(entries []ManagedDisk, wipe bool) {
+	fmt.Println("💽 Bloom-managed cluster disks:")
+	encrypted := false
+	for _, e := range entries {
+		device := e.Device
+		if device == "" {
+			device = "(device not resolved)"
+		}
+		if e.Mapper != "" {
+			device += " (LUKS " + e.Mapper + ")"
+			encrypted = true
+		}
+		fmt.Printf("   • %s → %s\n", device, e.MountPoint)
+	}
+	fmt.Println()
+	fmt.Println("   These mounts will be unmounted and their fstab entries removed.")
+	if encrypted {
+		fmt.Println("   Their LUKS mappings will be closed and their /etc/crypttab entries removed.")
+	}
+	if wipe {
+		fmt.Println("   ⚠️  The devices will also be WIPED (wipefs -a). Their data cannot be recovered.")
+	} else {
+		fmt.Println("   Filesystems and data on the devices are left intact.")
+	}
+	fmt.Println("   RKE2, CLUSTER_PREMOUNTED_DISKS and RANCHER_DISK are not touched.")
+}
+
+// TeardownBloomDisks unmounts the given CLUSTER_DISKS mounts and removes their
+// fstab entries without uninstalling RKE2. Encrypted disks also have their LUKS
+// mapping closed and their crypttab entry removed. When wipe is true the devices
+// (for encrypted disks, the disk holding the LUKS header) are also wiped with
+// wipefs. Every mount, fstab entry, mapping and device touched is printed.
+func TeardownBloomDisks(entries []ManagedDisk, wipe bool) error {
+	EnterCriticalSection("disk teardown and fstab modification")
+	defer ExitCriticalSection()
+
+	fstabContent, err := os.ReadFile("/etc/fstab")
+	if err != nil {
+		return fmt.Errorf("read fstab: %w", err)
+	}
+
+	backupPath := fmt.Sprintf("/etc/fstab.bak-%s", time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(backupPath, fstabContent, 0644); err != nil {
+		return fmt.Errorf("backup fstab: %w", err)
+	}
+	fmt.Printf("   Created fstab backup: %s\n", backupPath)
+
+	// Unmount first so a failed unmount leaves the fstab entry in place
+	var failed []string
+	unmounted := make(map[string]bool)
+	for _, e := range entries {
+		out, _ := exec.Command("findmnt", "--mountpoint", e.MountPoint, "--noheadings").Output()
+		if strings.TrimSpace(string(out)) == "" {
+			fmt.Printf("      • %s was not mounted\n", e.MountPoint)
+			unmounted[e.MountPoint] = true
+			continue
+		}
+		if out, err := exec.Command("umount", e.MountPoint).CombinedOutput(); err != nil {
+			fmt.Printf("      ⚠️  Failed to unmount %s: %s\n", e.MountPoint, strings.TrimSpace(string(out)))
+			failed = append(failed, e.MountPoint)
+			continue
+		}
+		fmt.Printf("      ✓ Unmounted %s\n", e.MountPoint)
+		unmounted[e.MountPoint] = true
+	}
+
+	var kept []string
+	for _, line := range strings.Split(string(fstabContent), "\n") {
+		if isManagedClusterDiskLine(line) {
+			if fields := strings.Fields(line); len(fields) >= 2 && unmounted[fields[1]] {
+				fmt.Printf("      ✓ Removed fstab entry: %s\n", strings.TrimSpace(line))
+				continue
+			}
+		}
+		kept = append(kept, line)
+	}
+	if err := os.WriteFile("/etc/fstab", []byte(strings.Join(kept, "\n")), 0644); err != nil {
+		return fmt.Errorf("update fstab: %w", err)
+	}
+
+	// Close the LUKS mappings of the released mounts; a mapping still open
+	// keeps its disk from being wiped
+	closed := make(map[string]bool)
+	for _, e := range entries {
+		if e.Mapper == "" || !unmounted[e.MountPoint] {
+			continue
+		}
+		if _, err := os.Stat(path.Join("/dev/mapper", e.Mapper)); err == nil {
+			if out, err := exec.Command("cryptsetup", "close", e.Mapper).CombinedOutput(); err != nil {
+				fmt.Printf("      ⚠️  Failed to close LUKS mapping %s: %s\n", e.Mapper, strings.TrimSpace(string(out)))
+				failed = append(failed, e.Mapper)
+				continue
+			}
+			fmt.Printf("      ✓ Closed LUKS mapping %s\n", e.Mapper)
+		}
+		closed[e.Mapper] = true
+	}
+	if len(closed) > 0 {
+		if err := removeBloomCrypttabEntries(closed); err != nil {
+			return err
+		}
+	}
+
+	if wipe {
+		for _, e := range entries {
+			if e.Device == "" || !unmounted[e.MountPoint] || (e.Mapper != "" && !closed[e.Mapper]) {
+				continue
+			}
+			if out, _ := exec.Command("findmnt", "--source", e.Device, "--noheadings").Output(); strings.TrimSpace(string(out)) != "" {
+				fmt.Printf("      ⚠️  SKIPPING wipe of %s: still mounted at: %s\n", e.Device, strings.TrimSpace(string(out)))
+				continue
+			}
+			if out, err := exec.Command("wipefs", "-a", e.Device).CombinedOutput(); err != nil {
+				fmt.Printf("      ⚠️  wipefs failed on %s: %s\n", e.Device, strings.TrimSpace(string(out)))
+				failed = append(failed, e.Device)
+				continue
+			}
+			fmt.Printf("      ✓ Wiped %s\n", e.Device)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("could not tear down: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// removeBloomCrypttabEntries removes the crypttab entries of the closed
+// mappings, keeping a backup of /etc/crypttab like the fstab one
+func removeBloomCrypttabEntries(mappers map[string]bool) error {
+	data, err := os.ReadFile("/etc/crypttab")
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read crypttab: %w", err)
+	}
+	kept, removed := removeCrypttabEntries(string(data), mappers)
+	if len(removed) == 0 {
+		return nil
+	}
+
+	backupPath := fmt.Sprintf("/etc/crypttab.bak-%s", time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return fmt.Errorf("backup crypttab: %w", err)
+	}
+	fmt.Printf("   Created crypttab backup: %s\n", backupPath)
+	if err := os.WriteFile("/etc/crypttab", []byte(kept), 0644); err != nil {
+		return fmt.Errorf("update crypttab: %w", err)
+	}
+	for _, line := range removed {
+		fmt.Printf("      ✓ Removed crypttab entry: %s\n", line)
+	}
+	return nil
+}
+
+// FilterManagedDisks narrows entries to the devices listed in clusterDisks
+// (comma-separated). An empty clusterDisks keeps every entry.
+func FilterManagedDisks(entries []ManagedDisk, clusterDisks string) []ManagedDisk {
+	if strings.TrimSpace(clusterDisks) == "" {
+		return entries
+	}
+
+	var filtered []ManagedDisk
+	for _, e := range entries {
+		for _, disk := range strings.Split(clusterDisks, ",") {
+			disk = strings.TrimSpace(disk)
+			if disk != "" && isDiskOrPartition(e.Device, disk) {
+				filtered = append(filtered, e)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// isDiskOrPartition reports whether device is disk itself or one of its
+// partitions: /dev/sda1 for /dev/sda, /dev/nvme0n1p1 for /dev/nvme0n1. Disks
+// whose names end in a digit put a "p" before the partition number, so
+// /dev/sdab and /dev/nvme0n10 are never partitions of /dev/sda or /dev/nvme0n1.
+func isDiskOrPartition(device, disk string) bool {
+	if device == disk {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(device, disk)
+	if !ok || disk == "" {
+		return false
+	}
+	if last := disk[len(disk)-1]; last >= '0' && last <= '9' {
+		if suffix, ok = strings.CutPrefix(suffix, "p"); !ok {
+			return false
+		}
+	}
+	return suffix != "" && strings.Trim(suffix, "0123456789") == ""
+}
@@ -0,0 +1,102 @@
+//go:build linux
+
+package runtime
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsDiskOrPartition(t *testing.T) {
+	tests := []struct {
+		device string
+		disk   string
+		want   bool
+	}{
+		{"/dev/sda", "/dev/sda", true},
+		{"/dev/sda1", "/dev/sda", true},
+		{"/dev/sda12", "/dev/sda", true},
+		{"/dev/sdab", "/dev/sda", false},
+		{"/dev/sdab1", "/dev/sda", false},
+		{"/dev/sd", "/dev/sda", false},
+		{"/dev/nvme0n1", "/dev/nvme0n1", true},
+		{"/dev/nvme0n1p1", "/dev/nvme0n1", true},
+		{"/dev/nvme0n1p", "/dev/nvme0n1", false},
+		{"/dev/nvme0n10", "/dev/nvme0n1", false},
+		{"/dev/nvme0n10p1", "/dev/nvme0n1", false},
+		{"/dev/mmcblk0p2", "/dev/mmcblk0", true},
+		{"/dev/mmcblk01", "/dev/mmcblk0", false},
+		{"", "/dev/sda", false},
+	}
+	for _, tt := range tests {
+		if got := isDiskOrPartition(tt.device, tt.disk); got != tt.want {
+			t.Errorf("isDiskOrPartition(%q, %q) = %v, want %v", tt.device, tt.disk, got, tt.want)
+		}
+	}
+}
+
+func TestFilterManagedDisks(t *testing.T) {
+	entries := []ManagedDisk{
+		{Device: "/dev/sda1", MountPoint: "/mnt/disk0"},
+		{Device: "/dev/sdab", MountPoint: "/mnt/disk1"},
+		{Device: "/dev/nvme0n1", MountPoint: "/mnt/disk2"},
+		{Device: "/dev/nvme0n10", MountPoint: "/mnt/disk3"},
+		{Device: "", MountPoint: "/mnt/disk4"},
+		{Device: "/dev/sdc", MountPoint: "/mnt/disk5", Mapper: "bloom-disk5"},
+	}
+
+	if got := FilterManagedDisks(entries, " "); !reflect.DeepEqual(got, entries) {
+		t.Errorf("FilterManagedDisks with no CLUSTER_DISKS = %v, want every entry", got)
+	}
+
+	got := FilterManagedDisks(entries, "/dev/sda, /dev/nvme0n1,/dev/sdc")
+	want := []ManagedDisk{entries[0], entries[2], entries[5]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterManagedDisks = %v, want %v", got, want)
+	}
+}
+
+func TestEncryptedManagedDisk(t *testing.T) {
+	crypttab := "# <target> <source> <key> <options>\n" +
+		"bloom-disk0 UUID=1111-aaaa /etc/bloom/disk.key luks,discard,nofail\n" +
+		"bloom-disk1 /dev/sdc /etc/bloom/disk.key luks,discard,nofail\n" +
+		"swap /dev/sda3 /dev/urandom swap\n"
+
+	mappers := []struct {
+		mountPoint string
+		device     string
+		want       string
+	}{
+		{"/mnt/disk0", "/dev/mapper/bloom-disk0", "bloom-disk0"}, // Open: fstab UUID resolves to the mapper
+		{"/mnt/disk1", "", "bloom-disk1"},                        // Closed: found through crypttab
+		{"/mnt/disk2", "/dev/sdd", ""},                           // Unencrypted
+		{"/mnt/disk2", "", ""},
+		{"/mnt/data", "", ""},
+	}
+	for _, tt := range mappers {
+		if got := luksMapperFor(tt.mountPoint, tt.device, crypttab); got != tt.want {
+			t.Errorf("luksMapperFor(%q, %q) = %q, want %q", tt.mountPoint, tt.device, got, tt.want)
+		}
+	}
+
+	if got := crypttabSource(crypttab, "bloom-disk0"); got != "UUID=1111-aaaa" {
+		t.Errorf("crypttabSource(bloom-disk0) = %q", got)
+	}
+	if got := luksBackingDevice("bloom-disk1", crypttab); got != "/dev/sdc" {
+		t.Errorf("luksBackingDevice(bloom-disk1) = %q, want /dev/sdc", got)
+	}
+
+	status := "/dev/mapper/bloom-disk0 is active and is in use.\n  type:    LUKS2\n  cipher:  aes-xts-plain64\n  device:  /dev/sdb\n  sector size:  512\n"
+	if got := parseCryptsetupStatusDevice(status); got != "/dev/sdb" {
+		t.Errorf("parseCryptsetupStatusDevice() = %q, want /dev/sdb", got)
+	}
+
+	kept, removed := removeCrypttabEntries(crypttab, map[string]bool{"bloom-disk0": true})
+	if strings.Contains(kept, "bloom-disk0") || !strings.Contains(kept, "bloom-disk1") || !strings.Contains(kept, "swap") {
+		t.Errorf("removeCrypttabEntries() kept %q", kept)
+	}
+	if len(removed) != 1 || !strings.HasPrefix(removed[0], "bloom-disk0 ") {
+		t.Errorf("removeCrypttabEntries() removed %q", removed)
+	}
+}
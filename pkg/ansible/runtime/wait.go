@@ -0,0 +1,214 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// waitPollInterval is how often WaitForCluster re-checks readiness
+const waitPollInterval = 10 * time.Second
+
+// cniDaemonSets are the node agent DaemonSets of the CNIs RKE2 can deploy
+var cniDaemonSets = []string{"cilium", "rke2-canal", "calico-node", "kube-flannel-ds", "rke2-flannel"}
+
+// WaitOptions configures WaitForCluster
+type WaitOptions struct {
+	Kubeconfig    string // Empty lets kubectl resolve KUBECONFIG / ~/.kube/config
+	ExpectedNodes int
+	Timeout       time.Duration
+	SkipLonghorn  bool // Clusters without Longhorn (e.g. NO_DISKS_FOR_CLUSTER)
+}
+
+// readinessCheck is one component WaitForCluster waits on. check returns a
+// short status for the progress line and whether the component is ready.
+type readinessCheck struct {
+	name  string
+	check func(kubectl []string) (string, bool)
+}
+
+// WaitForCluster polls the cluster until ExpectedNodes nodes are Ready and
+// CoreDNS, the CNI and the Longhorn manager are available, or Timeout elapses.
+func WaitForCluster(opts WaitOptions) error {
+	kubectl := kubectlCommand(opts.Kubeconfig)
+
+	checks := []readinessCheck{
+		{"Nodes", func(k []string) (string, bool) { return nodesReady(k, opts.ExpectedNodes) }},
+		{"CoreDNS", coreDNSReady},
+		{"CNI", cniReady},
+	}
+	if !opts.SkipLonghorn {
+		checks = append(checks, readinessCheck{"Longhorn manager", longhornManagerReady})
+	}
+
+	fmt.Printf("⏳ Waiting up to %s for %d Ready node(s) and core components...\n", opts.Timeout, opts.ExpectedNodes)
+
+	deadline := time.Now().Add(opts.Timeout)
+	last := make(map[string]string)
+	for {
+		var pending []string
+		for _, c := range checks {
+			status, ready := c.check(kubectl)
+			if last[c.name] != status {
+				icon := "⏳"
+				if ready {
+					icon = "✅"
+				}
+				fmt.Printf("   %s %s: %s\n", icon, c.name, status)
+				last[c.name] = status
+			}
+			if !ready {
+				pending = append(pending, fmt.Sprintf("%s (%s)", c.name, status))
+			}
+		}
+
+		if len(pending) == 0 {
+			fmt.Println("✅ Cluster is ready")
+			return nil
+		}
+		if time.Now().Add(waitPollInterval).After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for: %s", opts.Timeout, strings.Join(pending, ", "))
+		}
+		time.Sleep(waitPollInterval)
+	}
+}
+
+// kubectlCommand returns the kubectl invocation to use, preferring kubectl on
+// PATH and falling back to the binary RKE2 installs
+func kubectlCommand(kubeconfig string) []string {
+	bin := "kubectl"
+	if _, err := exec.LookPath(bin); err != nil {
+		if _, err := os.Stat(rke2Kubectl()); err == nil {
+			bin = rke2Kubectl()
+		}
+	}
+	if kubeconfig == "" && os.Getenv("KUBECONFIG") == "" {
+		if _, err := os.Stat("/etc/rancher/rke2/rke2.yaml"); err == nil {
+			kubeconfig = "/etc/rancher/rke2/rke2.yaml"
+		}
+	}
+	cmd := []string{bin}
+	if kubeconfig != "" {
+		cmd = append(cmd, "--kubeconfig", kubeconfig)
+	}
+	return cmd
+}
+
+// kubectlJSON runs a kubectl get with a short timeout and decodes its JSON
+// output into v, so a sluggish API server only delays a single poll
+func kubectlJSON(kubectl []string, v any, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	full := append(append(append([]string{}, kubectl[1:]...), args...), "--request-timeout=10s", "-o", "json")
+	out, err := exec.CommandContext(ctx, kubectl[0], full...).Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("kubectl %s timed out after 15s (partial output: %q)", strings.Join(args, " "), strings.TrimSpace(string(out)))
+	}
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return err
+	}
+	return json.Unmarshal(out, v)
+}
+
+func nodesReady(kubectl []string, expected int) (string, bool) {
+	var list struct {
+		Items []struct {
+			Status struct {
+				Conditions []struct {
+					Type   string `json:"type"`
+					Status string `json:"status"`
+				} `json:"conditions"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := kubectlJSON(kubectl, &list, "get", "nodes"); err != nil {
+		return "API not reachable", false
+	}
+	ready := 0
+	for _, n := range list.Items {
+		for _, c := range n.Status.Conditions {
+			if c.Type == "Ready" && c.Status == "True" {
+				ready++
+			}
+		}
+	}
+	return fmt.Sprintf("%d/%d Ready", ready, expected), ready >= expected
+}
+
+type deploymentList struct {
+	Items []struct {
+		Status struct {
+			Replicas          int `json:"replicas"`
+			AvailableReplicas int `json:"availableReplicas"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+func coreDNSReady(kubectl []string) (string, bool) {
+	var list deploymentList
+	if err := kubectlJSON(kubectl, &list, "get", "deployments", "-n", "kube-system", "-l", "k8s-app=kube-dns"); err != nil {
+		return "API not reachable", false
+	}
+	if len(list.Items) == 0 {
+		return "not deployed", false
+	}
+	s := list.Items[0].Status
+	return fmt.Sprintf("%d/%d available", s.AvailableReplicas, s.Replicas), s.Replicas > 0 && s.AvailableReplicas >= s.Replicas
+}
+
+type daemonSetList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			DesiredNumberScheduled int `json:"desiredNumberScheduled"`
+			NumberAvailable        int `json:"numberAvailable"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+func cniReady(kubectl []string) (string, bool) {
+	var list daemonSetList
+	if err := kubectlJSON(kubectl, &list, "get", "daemonsets", "--all-namespaces"); err != nil {
+		return "API not reachable", false
+	}
+	for _, ds := range list.Items {
+		if !contains(cniDaemonSets, ds.Metadata.Name) {
+			continue
+		}
+		s := ds.Status
+		return fmt.Sprintf("%s %d/%d available", ds.Metadata.Name, s.NumberAvailable, s.DesiredNumberScheduled),
+			s.DesiredNumberScheduled > 0 && s.NumberAvailable >= s.DesiredNumberScheduled
+	}
+	return "not deployed", false
+}
+
+func longhornManagerReady(kubectl []string) (string, bool) {
+	var list daemonSetList
+	if err := kubectlJSON(kubectl, &list, "get", "daemonsets", "-n", "longhorn-system", "--field-selector", "metadata.name=longhorn-manager"); err != nil {
+		return "API not reachable", false
+	}
+	if len(list.Items) == 0 {
+		return "not deployed", false
+	}
+	s := list.Items[0].Status
+	return fmt.Sprintf("%d/%d available", s.NumberAvailable, s.DesiredNumberScheduled),
+		s.DesiredNumberScheduled > 0 && s.NumberAvailable >= s.DesiredNumberScheduled
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// cidrKeys are the RKE2 networks that must not collide with a host network
+var cidrKeys = []string{"RKE2_CLUSTER_CIDR", "RKE2_SERVICE_CIDR"}
+
+// cniInterfacePrefixes name the interfaces the CNI creates inside the pod
+// network; a re-run on a deployed node must not see them as collisions
+var cniInterfacePrefixes = []string{"cilium", "lxc", "cni", "flannel", "cali", "vxlan", "tunl", "kube-ipvs", "nodelocaldns"}
+
+// HostNetwork is a network attached to one of the node's interfaces
+type HostNetwork struct {
+	Interface string
+	Net       *net.IPNet
+}
+
+func (h HostNetwork) String() string {
+	return fmt.Sprintf("%s on %s", h.Net, h.Interface)
+}
+
+// HostNetworks lists the networks of the node's up interfaces, leaving out
+// loopback, link-local and CNI-owned interfaces
+func HostNetworks() ([]HostNetwork, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("listing network interfaces: %w", err)
+	}
+
+	var networks []HostNetwork
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 || isCNIInterface(iface.Name) {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, fmt.Errorf("reading addresses of %s: %w", iface.Name, err)
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			networks = append(networks, HostNetwork{
+				Interface: iface.Name,
+				Net:       &net.IPNet{IP: ipNet.IP.Mask(ipNet.Mask), Mask: ipNet.Mask},
+			})
+		}
+	}
+	return networks, nil
+}
+
+func isCNIInterface(name string) bool {
+	for _, prefix := range cniInterfacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// networksOverlap reports whether a and b share any address
+func networksOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// overlappingNetworks returns the host networks that overlap cidr
+func overlappingNetworks(cidr *net.IPNet, hostNets []HostNetwork) []HostNetwork {
+	var overlaps []HostNetwork
+	for _, h := range hostNets {
+		if networksOverlap(cidr, h.Net) {
+			overlaps = append(overlaps, h)
+		}
+	}
+	return overlaps
+}
+
+// autoCIDRCandidates lists the IPv4 ranges AUTO_CIDR picks from, starting
+// with the defaults so an unaffected node keeps them
+func autoCIDRCandidates() []*net.IPNet {
+	var candidates []*net.IPNet
+	add := func(a, b int) {
+		candidates = append(candidates, &net.IPNet{IP: net.IPv4(byte(a), byte(b), 0, 0).To4(), Mask: net.CIDRMask(16, 32)})
+	}
+	for b := 242; b <= 254; b++ {
+		add(10, b)
+	}
+	for b := 100; b < 242; b++ {
+		add(10, b)
+	}
+	for b := 16; b <= 31; b++ {
+		add(172, b)
+	}
+	return candidates
+}
+
+// pickFreeCIDR returns the first candidate that overlaps neither a host
+// network nor one of the networks already taken
+func pickFreeCIDR(hostNets []HostNetwork, taken []*net.IPNet) *net.IPNet {
+	for _, candidate := range autoCIDRCandidates() {
+		if len(overlappingNetworks(candidate, hostNets)) > 0 {
+			continue
+		}
+		free := true
+		for _, t := range taken {
+			if networksOverlap(candidate, t) {
+				free = false
+				break
+			}
+		}
+		if free {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// ResolveClusterCIDRs checks RKE2_CLUSTER_CIDR and RKE2_SERVICE_CIDR against
+// the host networks. An overlap is an error unless AUTO_CIDR is set on the
+// first node, in which case each colliding IPv4 range is replaced with a free
+// private /16 in cfg. It returns one line per range it changed.
+func ResolveClusterCIDRs(cfg Config, hostNets []HostNetwork) ([]string, error) {
+	auto, _ := cfg["AUTO_CIDR"].(bool)
+	firstNode := true
+	if v, ok := cfg["FIRST_NODE"].(bool); ok {
+		firstNode = v
+	}
+
+	var changes []string
+	var taken []*net.IPNet
+	for _, key := range cidrKeys {
+		value, _ := cfg[key].(string)
+		if value == "" {
+			continue
+		}
+		items := strings.Split(value, ",")
+		for i, item := range items {
+			_, ipNet, err := net.ParseCIDR(strings.TrimSpace(item))
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid CIDR %s", key, item)
+			}
+			overlaps := overlappingNetworks(ipNet, hostNets)
+			if len(overlaps) == 0 {
+				taken = append(taken, ipNet)
+				continue
+			}
+
+			collision := fmt.Sprintf("%s (%s) overlaps the host network %s", key, ipNet, overlaps[0])
+			switch {
+			case !auto:
+				return nil, fmt.Errorf("%s; set %s to a free range or set AUTO_CIDR: true", collision, key)
+			case !firstNode:
+				return nil, fmt.Errorf("%s; AUTO_CIDR only picks ranges on the first node, set %s to the value the first node uses", collision, key)
+			case ipNet.IP.To4() == nil:
+				return nil, fmt.Errorf("%s; AUTO_CIDR only picks IPv4 ranges, set %s to a free IPv6 range", collision, key)
+			}
+
+			free := pickFreeCIDR(hostNets, append(taken, otherCIDRs(cfg, key)...))
+			if free == nil {
+				return nil, fmt.Errorf("%s and AUTO_CIDR found no free private /16 range; set %s explicitly", collision, key)
+			}
+			items[i] = free.String()
+			taken = append(taken, free)
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s (%s overlaps %s)", key, ipNet, free, ipNet, overlaps[0]))
+		}
+		cfg[key] = strings.Join(items, ",")
+	}
+	return changes, nil
+}
+
+// otherCIDRs returns the parsed ranges of the CIDR keys other than key, so a
+// replacement never lands on a range the other network still uses
+func otherCIDRs(cfg Config, key string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, other := range cidrKeys {
+		if other == key {
+			continue
+		}
+		value, _ := cfg[other].(string)
+		for _, item := range strings.Split(value, ",") {
+			if _, ipNet, err := net.ParseCIDR(strings.TrimSpace(item)); err == nil {
+				nets = append(nets, ipNet)
+			}
+		}
+	}
+	return nets
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func hostNet(iface, cidr string) HostNetwork {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return HostNetwork{Interface: iface, Net: ipNet}
+}
+
+func TestOverlappingNetworks(t *testing.T) {
+	tests := []struct {
+		name     string
+		cidr     string
+		hostNets []HostNetwork
+		want     []string
+	}{
+		{name: "no host networks", cidr: "10.242.0.0/16"},
+		{name: "disjoint", cidr: "10.242.0.0/16", hostNets: []HostNetwork{hostNet("eth0", "192.168.1.0/24")}},
+		{name: "host inside cidr", cidr: "10.242.0.0/16", hostNets: []HostNetwork{hostNet("eth0", "10.242.8.0/24")}, want: []string{"eth0"}},
+		{name: "cidr inside host", cidr: "10.242.0.0/16", hostNets: []HostNetwork{hostNet("eth0", "10.0.0.0/8")}, want: []string{"eth0"}},
+		{name: "adjacent ranges", cidr: "10.242.0.0/16", hostNets: []HostNetwork{hostNet("eth0", "10.243.0.0/16"), hostNet("eth1", "10.241.0.0/16")}},
+		{name: "several interfaces", cidr: "10.242.0.0/16", hostNets: []HostNetwork{hostNet("eth0", "192.168.1.0/24"), hostNet("eth1", "10.242.1.0/24"), hostNet("ib0", "10.242.2.0/24")}, want: []string{"eth1", "ib0"}},
+		{name: "ipv6 overlap", cidr: "fd00:10:242::/56", hostNets: []HostNetwork{hostNet("eth0", "fd00:10:242::/64")}, want: []string{"eth0"}},
+		{name: "ipv4 against ipv6", cidr: "10.242.0.0/16", hostNets: []HostNetwork{hostNet("eth0", "fd00:10:242::/64")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, cidr, _ := net.ParseCIDR(tt.cidr)
+			var got []string
+			for _, h := range overlappingNetworks(cidr, tt.hostNets) {
+				got = append(got, h.Interface)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("overlappingNetworks(%s) = %v, want %v", tt.cidr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveClusterCIDRs(t *testing.T) {
+	base := Config{
+		"FIRST_NODE":        true,
+		"RKE2_CLUSTER_CIDR": "10.242.0.0/16",
+		"RKE2_SERVICE_CIDR": "10.243.0.0/16",
+	}
+
+	tests := []struct {
+		name        string
+		overrides   Config
+		hostNets    []HostNetwork
+		wantErr     string
+		wantCluster string
+		wantService string
+	}{
+		{
+			name:        "no collision",
+			hostNets:    []HostNetwork{hostNet("eth0", "192.168.1.0/24")},
+			wantCluster: "10.242.0.0/16",
+			wantService: "10.243.0.0/16",
+		},
+		{
+			name:     "collision without AUTO_CIDR",
+			hostNets: []HostNetwork{hostNet("eth1", "10.242.5.0/24")},
+			wantErr:  "RKE2_CLUSTER_CIDR (10.242.0.0/16) overlaps the host network 10.242.5.0/24 on eth1",
+		},
+		{
+			name:        "AUTO_CIDR replaces the cluster range",
+			overrides:   Config{"AUTO_CIDR": true},
+			hostNets:    []HostNetwork{hostNet("eth1", "10.242.5.0/24")},
+			wantCluster: "10.244.0.0/16",
+			wantService: "10.243.0.0/16",
+		},
+		{
+			name:        "AUTO_CIDR replaces both ranges",
+			overrides:   Config{"AUTO_CIDR": true},
+			hostNets:    []HostNetwork{hostNet("eth0", "10.240.0.0/12")},
+			wantCluster: "10.100.0.0/16",
+			wantService: "10.101.0.0/16",
+		},
+		{
+			name:        "AUTO_CIDR falls back to 172.16.0.0/12",
+			overrides:   Config{"AUTO_CIDR": true},
+			hostNets:    []HostNetwork{hostNet("eth0", "10.0.0.0/8")},
+			wantCluster: "172.16.0.0/16",
+			wantService: "172.17.0.0/16",
+		},
+		{
+			name:        "AUTO_CIDR keeps the IPv6 half of a dualstack range",
+			overrides:   Config{"AUTO_CIDR": true, "RKE2_CLUSTER_CIDR": "10.242.0.0/16,fd00:10:242::/56"},
+			hostNets:    []HostNetwork{hostNet("eth0", "10.242.0.0/24")},
+			wantCluster: "10.244.0.0/16,fd00:10:242::/56",
+			wantService: "10.243.0.0/16",
+		},
+		{
+			name:      "AUTO_CIDR does not pick IPv6 ranges",
+			overrides: Config{"AUTO_CIDR": true, "RKE2_CLUSTER_CIDR": "fd00:10:242::/56"},
+			hostNets:  []HostNetwork{hostNet("eth0", "fd00:10:242::/64")},
+			wantErr:   "AUTO_CIDR only picks IPv4 ranges",
+		},
+		{
+			name:      "AUTO_CIDR on an additional node",
+			overrides: Config{"AUTO_CIDR": true, "FIRST_NODE": false},
+			hostNets:  []HostNetwork{hostNet("eth0", "10.243.0.0/24")},
+			wantErr:   "AUTO_CIDR only picks ranges on the first node",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{}
+			for k, v := range base {
+				cfg[k] = v
+			}
+			for k, v := range tt.overrides {
+				cfg[k] = v
+			}
+
+			_, err := ResolveClusterCIDRs(cfg, tt.hostNets)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg["RKE2_CLUSTER_CIDR"] != tt.wantCluster || cfg["RKE2_SERVICE_CIDR"] != tt.wantService {
+				t.Errorf("got cluster %v service %v, want %s and %s", cfg["RKE2_CLUSTER_CIDR"], cfg["RKE2_SERVICE_CIDR"], tt.wantCluster, tt.wantService)
+			}
+		})
+	}
+}
@@ -1,22 +1,29 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
-// LoadConfig reads and parses a bloom.yaml configuration file
-func LoadConfig(filepath string) (Config, error) {
-	data, err := os.ReadFile(filepath)
+// LoadConfig reads and parses a bloom configuration file in YAML or JSON
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read config file: %w", err)
 	}
 
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("parse config file: %w", err)
+	config, err := ParseConfig(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
 	}
 
 	// Apply defaults from schema
@@ -24,9 +31,169 @@ func LoadConfig(filepath string) (Config, error) {
 		return nil, fmt.Errorf("apply defaults: %w", err)
 	}
 
+	Normalize(config)
+
+	return config, nil
+}
+
+// ParseConfig parses config content as JSON when path ends in .json, as YAML
+// when it ends in .yaml or .yml, and otherwise as JSON only when the content
+// starts with '{'
+func ParseConfig(path string, data []byte) (Config, error) {
+	if isJSONConfig(path, data) {
+		return ParseJSON(data)
+	}
+	return ParseYAML(data)
+}
+
+func isJSONConfig(path string, data []byte) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return true
+	case ".yaml", ".yml":
+		return false
+	}
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// ParseJSON parses a JSON config into the same value types ParseYAML
+// produces, so validation behaves the same for either format
+func ParseJSON(data []byte) (Config, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return Config{}, nil
+	}
+	var raw map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("%w%s", err, jsonErrorSnippet(data, err))
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected content after the top-level JSON object")
+	}
+
+	config := Config{}
+	for key, value := range raw {
+		config[key] = fromJSONValue(value)
+	}
+	return config, nil
+}
+
+// fromJSONValue converts json.Number to int (or float64 for fractions), the
+// types yaml.v3 decodes numbers to
+func fromJSONValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := strconv.Atoi(v.String()); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = fromJSONValue(v[i])
+		}
+		return v
+	case map[string]any:
+		for key := range v {
+			v[key] = fromJSONValue(v[key])
+		}
+		return v
+	}
+	return value
+}
+
+// jsonErrorSnippet returns the config lines around a JSON syntax or type
+// error, or "" when the error carries no offset
+func jsonErrorSnippet(data []byte, err error) string {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return ""
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line := bytes.Count(data[:offset], []byte("\n")) + 1
+	return fmt.Sprintf(" (line %d)%s", line, lineSnippet(data, line))
+}
+
+// ParseYAML parses bloom.yaml content without applying defaults. Parse errors
+// include the lines around the one the error points at.
+func ParseYAML(data []byte) (Config, error) {
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%w%s", err, yamlErrorSnippet(data, err))
+	}
+	if config == nil {
+		// An empty file parses to a nil map; let validation report the missing keys
+		config = Config{}
+	}
 	return config, nil
 }
 
+// yamlErrorLine matches the line number yaml.v3 includes in parse errors
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// yamlErrorSnippet returns the config lines around the line a YAML error
+// points at, or "" when the error carries no line number
+func yamlErrorSnippet(data []byte, err error) string {
+	matches := yamlErrorLine.FindStringSubmatch(err.Error())
+	if len(matches) < 2 {
+		return ""
+	}
+	line, convErr := strconv.Atoi(matches[1])
+	if convErr != nil {
+		return ""
+	}
+	return lineSnippet(data, line)
+}
+
+// lineSnippet returns line and the one before it, with line marked
+func lineSnippet(data []byte, line int) string {
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+
+	// yaml.v3 sometimes reports the line after the mistake, so include the previous one
+	var sb strings.Builder
+	for n := max(line-1, 1); n <= line; n++ {
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&sb, "\n  %s %4d | %s", marker, n, lines[n-1])
+	}
+	return sb.String()
+}
+
+// Normalize rewrites common user-supplied variants of config values into the
+// canonical form expected by validation and the playbooks
+func Normalize(config Config) {
+	if domain, ok := config["DOMAIN"].(string); ok {
+		config["DOMAIN"] = NormalizeDomain(domain)
+	}
+}
+
+// NormalizeDomain strips surrounding whitespace, a URL scheme and trailing
+// slashes, so "https://cluster.example.com/" becomes "cluster.example.com".
+// Ports and paths are left in place so Validate can reject them explicitly.
+func NormalizeDomain(domain string) string {
+	domain = strings.TrimSpace(domain)
+	if i := strings.Index(domain, "://"); i >= 0 {
+		domain = domain[i+3:]
+	}
+	return strings.TrimRight(domain, "/")
+}
+
 // applyDefaults applies default values from the schema to the config
 func applyDefaults(config *Config) error {
 	// Load schema to get default values
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"cluster.example.com", "cluster.example.com"},
+		{"https://cluster.example.com", "cluster.example.com"},
+		{"https://cluster.example.com/", "cluster.example.com"},
+		{"http://cluster.example.com//", "cluster.example.com"},
+		{"  cluster.example.com/ ", "cluster.example.com"},
+		{"", ""},
+		// Ports and paths are preserved so validation can reject them
+		{"https://cluster.example.com:8443/", "cluster.example.com:8443"},
+		{"https://cluster.example.com/path", "cluster.example.com/path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := NormalizeDomain(tt.input); got != tt.want {
+				t.Errorf("NormalizeDomain(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_NormalizesDomain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bloom.yaml")
+	content := "FIRST_NODE: true\nDOMAIN: https://cluster.example.com/\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg["DOMAIN"] != "cluster.example.com" {
+		t.Errorf("Expected DOMAIN to be normalized to cluster.example.com, got %v", cfg["DOMAIN"])
+	}
+}
+
+func TestLoadConfig_YAMLErrorShowsLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bloom.yaml")
+	content := "FIRST_NODE: true\nGPU_NODE: false\nDOMAIN: cluster.example.com: extra\nCLUSTER_SIZE: small\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	_, err := LoadConfig(path)
+	if err == nil {
+		t.Fatal("Expected parse error for malformed YAML")
+	}
+	msg := err.Error()
+	for _, want := range []string{path, "line 3", ">    3 | DOMAIN: cluster.example.com: extra", "     2 | GPU_NODE: false"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Expected error to contain %q, got:\n%s", want, msg)
+		}
+	}
+}
+
+func TestLoadConfig_EmptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bloom.yaml")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg == nil {
+		t.Fatal("Expected defaults to be applied to an empty config")
+	}
+}
+
+func TestLoadConfig_JSONMatchesYAML(t *testing.T) {
+	dir := t.TempDir()
+	yamlContent := `FIRST_NODE: true
+GPU_NODE: false
+DOMAIN: https://cluster.example.com/
+CLUSTER_DISKS: /dev/nvme0n1,/dev/nvme1n1
+MAX_CLOCK_SKEW_MS: 250
+NTP_SERVERS:
+  - ntp1.internal
+  - 10.0.0.5
+`
+	jsonContent := `{
+  "FIRST_NODE": true,
+  "GPU_NODE": false,
+  "DOMAIN": "https://cluster.example.com/",
+  "CLUSTER_DISKS": "/dev/nvme0n1,/dev/nvme1n1",
+  "MAX_CLOCK_SKEW_MS": 250,
+  "NTP_SERVERS": ["ntp1.internal", "10.0.0.5"]
+}
+`
+	files := map[string]string{
+		"bloom.yaml": yamlContent,
+		"bloom.json": jsonContent,
+		// No known extension: the leading '{' selects JSON
+		"bloom.conf": jsonContent,
+	}
+	loaded := map[string]Config{}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("LoadConfig(%s) failed: %v", name, err)
+		}
+		loaded[name] = cfg
+	}
+
+	want := loaded["bloom.yaml"]
+	if want["MAX_CLOCK_SKEW_MS"] != 250 {
+		t.Fatalf("Expected MAX_CLOCK_SKEW_MS to parse as int 250, got %#v", want["MAX_CLOCK_SKEW_MS"])
+	}
+	for _, name := range []string{"bloom.json", "bloom.conf"} {
+		got := loaded[name]
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s parsed differently from bloom.yaml:\n got %#v\nwant %#v", name, got, want)
+		}
+		if gotErrs, wantErrs := Validate(got), Validate(want); !reflect.DeepEqual(gotErrs, wantErrs) {
+			t.Errorf("%s validated differently from bloom.yaml:\n got %v\nwant %v", name, gotErrs, wantErrs)
+		}
+	}
+}
+
+func TestLoadConfig_JSONErrorShowsLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bloom.json")
+	content := "{\n  \"FIRST_NODE\": true,\n  \"DOMAIN\": \"cluster.example.com\"\n  \"GPU_NODE\": false\n}\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	_, err := LoadConfig(path)
+	if err == nil {
+		t.Fatal("Expected parse error for malformed JSON")
+	}
+	msg := err.Error()
+	for _, want := range []string{path, "line 4", `>    4 |   "GPU_NODE": false`} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Expected error to contain %q, got:\n%s", want, msg)
+		}
+	}
+}
+
+func TestParseConfig_DetectsFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+		json bool
+	}{
+		{"bloom.json", "FIRST_NODE: true", true},
+		{"bloom.JSON", "{}", true},
+		{"bloom.yaml", "{FIRST_NODE: true}", false},
+		{"bloom.yml", "FIRST_NODE: true", false},
+		{"bloom", "  \n{\"FIRST_NODE\": true}", true},
+		{"bloom", "FIRST_NODE: true", false},
+		{"-", "", false},
+	}
+	for _, tt := range tests {
+		if got := isJSONConfig(tt.path, []byte(tt.data)); got != tt.json {
+			t.Errorf("isJSONConfig(%q, %q) = %v, want %v", tt.path, tt.data, got, tt.json)
+		}
+	}
+}
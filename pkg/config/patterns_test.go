@@ -101,10 +101,70 @@ func TestIPv4Pattern(t *testing.T) {
 	testPatternWithExamples(t, "ipv4")
 }
 
+func TestHostOrIPv4Pattern(t *testing.T) {
+	testPatternWithExamples(t, "hostOrIpv4")
+}
+
+func TestK8sNamespacePattern(t *testing.T) {
+	testPatternWithExamples(t, "k8sNamespace")
+}
+
+func TestK8sResourceNamePattern(t *testing.T) {
+	testPatternWithExamples(t, "k8sResourceName")
+}
+
+func TestDurationPattern(t *testing.T) {
+	testPatternWithExamples(t, "duration")
+}
+
+func TestOptionalDurationPattern(t *testing.T) {
+	testPatternWithExamples(t, "optionalDuration")
+}
+
+func TestClusterCidrPattern(t *testing.T) {
+	testPatternWithExamples(t, "clusterCidr")
+}
+
+func TestCertValidityDaysPattern(t *testing.T) {
+	testPatternWithExamples(t, "certValidityDays")
+}
+
+func TestCertKeyBitsPattern(t *testing.T) {
+	testPatternWithExamples(t, "certKeyBits")
+}
+
+func TestRetryCountPattern(t *testing.T) {
+	testPatternWithExamples(t, "retryCount")
+}
+
+func TestClockSkewMsPattern(t *testing.T) {
+	testPatternWithExamples(t, "clockSkewMs")
+}
+
+func TestFilePathPattern(t *testing.T) {
+	testPatternWithExamples(t, "filePath")
+}
+
+func TestDataDirPathPattern(t *testing.T) {
+	testPatternWithExamples(t, "dataDirPath")
+}
+
 func TestURLPattern(t *testing.T) {
 	testPatternWithExamples(t, "url")
 }
 
+func TestProxyURLPattern(t *testing.T) {
+	testPatternWithExamples(t, "proxyURL")
+}
+
+func TestNoProxyListPattern(t *testing.T) {
+	testPatternWithExamples(t, "noProxyList")
+}
+
+func TestHomeRelativePathPattern(t *testing.T) {
+	testPatternWithExamples(t, "homeRelativePath")
+}
+
 func TestDevicePathPattern(t *testing.T) {
 	testPatternWithExamples(t, "devicePath")
 }
@@ -125,6 +185,27 @@ func TestRKE2VersionPattern(t *testing.T) {
 	testPatternWithExamples(t, "rke2Version")
 }
 
+func TestCronSchedulePattern(t *testing.T) {
+	testPatternWithExamples(t, "cronSchedule")
+}
+
+func TestSnapshotRetentionPattern(t *testing.T) {
+	testPatternWithExamples(t, "snapshotRetention")
+}
+
+func TestContainerLogPatterns(t *testing.T) {
+	testPatternWithExamples(t, "containerLogSize")
+	testPatternWithExamples(t, "containerLogFiles")
+}
+
+func TestCNIMTUPattern(t *testing.T) {
+	testPatternWithExamples(t, "cniMtu")
+}
+
+func TestNodeCountPattern(t *testing.T) {
+	testPatternWithExamples(t, "nodeCount")
+}
+
 // TestAllTypesHaveExamples ensures every type in the schema has both valid and invalid examples
 func TestAllTypesHaveExamples(t *testing.T) {
 	schemaFile := loadSchemaFile(t)
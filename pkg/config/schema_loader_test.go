@@ -14,10 +14,10 @@ func TestLoadSchema(t *testing.T) {
 		t.Fatal("LoadSchema() returned no arguments")
 	}
 
-	// Check that we have expected number of fields (38 fields in schema including
+	// Check that we have expected number of fields (105 fields in schema including
 	// CLUSTER_SIZE, AIM_HARDWARE_FAMILY, GPU_STACK_FAMILY and ROCM_ALLOW_VERSION_MISMATCH)
-	if len(args) != 38 {
-		t.Errorf("Expected 38 arguments, got %d", len(args))
+	if len(args) != 105 {
+		t.Errorf("Expected 105 arguments, got %d", len(args))
 	}
 
 	// Verify critical fields are present
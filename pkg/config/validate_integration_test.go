@@ -148,7 +148,7 @@ func buildConfigWithField(fieldName string, value string) Config {
 	var config Config
 
 	// Determine which base config to use based on field
-	if fieldName == "SERVER_IP" || fieldName == "JOIN_TOKEN" || fieldName == "CONTROL_PLANE" {
+	if fieldName == "SERVER_IP" || fieldName == "JOIN_TOKEN" || fieldName == "JOIN_TOKEN_FILE" || fieldName == "CONTROL_PLANE" {
 		config = getAdditionalNodeConfig()
 	} else {
 		config = getBaseValidConfig()
@@ -176,12 +176,33 @@ func buildConfigWithField(fieldName string, value string) Config {
 		if value == "true" || value == "True" {
 			delete(config, "CERT_OPTION")
 		}
-	case "ROCM_BASE_URL", "ROCM_DEB_PACKAGE":
+	case "ROCM_BASE_URL", "ROCM_DEB_PACKAGE", "ROCM_DETECT_RETRIES":
 		config["GPU_NODE"] = true
+	case "RKE2_ARTIFACT_PATH":
+		// Required once AIRGAP is on, so only enable it for non-empty values
+		config["AIRGAP"] = value != ""
+	case "DISK_ENCRYPTION_KEY_FILE":
+		config["ENCRYPT_DISKS"] = value != ""
 	case "CLUSTER_DISKS":
 		delete(config, "NO_DISKS_FOR_CLUSTER")
 	case "CLUSTER_PREMOUNTED_DISKS":
 		delete(config, "NO_DISKS_FOR_CLUSTER")
+	case "RKE2_CLUSTER_CIDR", "RKE2_SERVICE_CIDR":
+		// Match RKE2_IP_FAMILY and the other CIDR to the family of the value
+		other := "RKE2_SERVICE_CIDR"
+		if fieldName == other {
+			other = "RKE2_CLUSTER_CIDR"
+		}
+		switch {
+		case strings.Contains(value, ","):
+			config["RKE2_IP_FAMILY"] = "dualstack"
+			config[other] = "10.99.0.0/16,fd00:99::/112"
+		case strings.Contains(value, ":"):
+			config["RKE2_IP_FAMILY"] = "ipv6"
+			config[other] = "fd00:99::/112"
+		default:
+			config[other] = "10.99.0.0/16"
+		}
 	}
 
 	return config
@@ -3,7 +3,12 @@ package config
 import (
 	_ "embed"
 	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -23,6 +28,37 @@ type SchemaTypes struct {
 
 var cachedPatterns map[string]*regexp.Regexp
 
+// rke2Components are the packaged RKE2 components that can be listed in
+// DISABLE_COMPONENTS (the values accepted by the RKE2 'disable' option)
+var rke2Components = []string{
+	"rke2-coredns",
+	"rke2-ingress-nginx",
+	"rke2-metrics-server",
+	"rke2-runtimeclasses",
+	"rke2-snapshot-controller",
+	"rke2-snapshot-controller-crd",
+	"rke2-snapshot-validation-webhook",
+	"rke2-traefik",
+}
+
+// sysctlKeyPattern matches dotted kernel parameter names such as vm.max_map_count
+var sysctlKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_\-]*(\.[a-zA-Z0-9_\-]+)+$`)
+
+// sha256FingerprintPattern matches a SHA-256 certificate fingerprint as 64 hex
+// digits, either plain or colon-separated as printed by openssl
+var sha256FingerprintPattern = regexp.MustCompile(`^([0-9A-Fa-f]{64}|([0-9A-Fa-f]{2}:){31}[0-9A-Fa-f]{2})$`)
+
+// registryHostPattern matches the registry names containerd mirrors are keyed
+// by: a hostname with optional port, or * for every registry
+var registryHostPattern = regexp.MustCompile(`^(\*|[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?(:[0-9]{1,5})?)$`)
+
+// removedKeys maps configuration keys from earlier releases that no longer
+// have any effect to guidance on what to do instead
+var removedKeys = map[string]string{
+	"ONEPASSWORD_CONNECT_TOKEN": "1Password Connect is not used by this version - ClusterForge secrets are managed by OpenBao. Remove this key",
+	"ONEPASSWORD_CONNECT_HOST":  "1Password Connect is not used by this version - ClusterForge secrets are managed by OpenBao. Remove this key",
+}
+
 // loadTypePatterns loads regex patterns from embedded schema
 func loadTypePatterns() (map[string]*regexp.Regexp, error) {
 	if cachedPatterns != nil {
@@ -67,6 +103,10 @@ func Validate(cfg Config) []string {
 	// Check for unknown keys in config
 	for key := range cfg {
 		if !validKeys[key] {
+			if guidance, removed := removedKeys[key]; removed {
+				errors = append(errors, fmt.Sprintf("%s is no longer supported: %s", key, guidance))
+				continue
+			}
 			errors = append(errors, fmt.Sprintf("Unknown configuration key: %s", key))
 		}
 	}
@@ -145,6 +185,18 @@ func Validate(cfg Config) []string {
 						}
 					}
 				}
+			case "domain":
+				// DOMAIN is used to build hostnames such as kc.<DOMAIN>, so a
+				// port or path would produce malformed issuer URLs and SANs
+				if isString && strVal != "" {
+					if strings.Contains(strVal, "/") {
+						errors = append(errors, fmt.Sprintf("%s must be a bare domain name without a path (e.g., cluster.example.com). Found: %s", arg.Key, strVal))
+					} else if strings.Contains(strVal, ":") {
+						errors = append(errors, fmt.Sprintf("%s must be a bare domain name without a port (e.g., cluster.example.com). Found: %s", arg.Key, strVal))
+					} else if pattern, ok := patterns[arg.Type]; ok && !pattern.MatchString(strVal) {
+						errors = append(errors, fmt.Sprintf("invalid %s format: %s", arg.Type, strVal))
+					}
+				}
 			case "clusterListenIp":
 				// Special validation for CLUSTER_LISTEN_IP - supports string only (IP or CIDR)
 				if isString {
@@ -165,6 +217,10 @@ func Validate(cfg Config) []string {
 					errors = append(errors, fmt.Sprintf("CLUSTER_LISTEN_IP must be a string (IP address or CIDR), got %T", value))
 				}
 			default:
+				// Numeric types arrive as ints from YAML
+				if intVal, isInt := value.(int); isInt {
+					strVal, isString = strconv.Itoa(intVal), true
+				}
 				// Check if this type has a pattern
 				if isString && strVal != "" {
 					if pattern, ok := patterns[arg.Type]; ok {
@@ -217,6 +273,78 @@ func Validate(cfg Config) []string {
 		}
 	}
 
+	// API_SERVER_SANS entries end up in the certificate SAN list and RKE2 tls-san,
+	// and NTP_SERVERS entries in chrony.conf, so each one must be a bare
+	// hostname or IPv4 address. chrony also takes IPv6 server addresses.
+	for _, key := range []string{"API_SERVER_SANS", "NTP_SERVERS"} {
+		hosts, exists := cfg[key]
+		if !exists || hosts == nil {
+			continue
+		}
+		hostPattern := patterns["hostOrIpv4"]
+		for i, host := range stringListValue(hosts) {
+			if host == "" || hostPattern == nil {
+				continue
+			}
+			if key == "NTP_SERVERS" && strings.Contains(host, ":") && net.ParseIP(host) != nil {
+				continue
+			}
+			if !hostPattern.MatchString(host) {
+				what := "a hostname or IPv4 address"
+				if key == "NTP_SERVERS" {
+					what = "a hostname, IPv4 or IPv6 address"
+				}
+				errors = append(errors, fmt.Sprintf("%s[%d]: must be %s without scheme or port. Found: %s", key, i, what, host))
+			}
+		}
+	}
+
+	errors = append(errors, validateOIDCProviders(cfg)...)
+	errors = append(errors, validateSysctls(cfg)...)
+	errors = append(errors, validateRegistryMirrors(cfg)...)
+
+	// RKE2 silently ignores unknown names in 'disable', so catch typos here
+	if components, exists := cfg["DISABLE_COMPONENTS"]; exists && components != nil {
+		for i, component := range stringListValue(components) {
+			if component != "" && !contains(rke2Components, component) {
+				errors = append(errors, fmt.Sprintf("DISABLE_COMPONENTS[%d]: unknown RKE2 component %q. Valid components: %s", i, component, strings.Join(rke2Components, ", ")))
+			}
+		}
+	}
+
+	errors = append(errors, validateServerIPs(cfg, patterns)...)
+	errors = append(errors, validateJoinToken(cfg)...)
+	errors = append(errors, validateRKE2Network(cfg, schema, patterns)...)
+	errors = append(errors, validateRKE2DataDir(cfg)...)
+	errors = append(errors, validateMetalLBRange(cfg, patterns)...)
+	errors = append(errors, validateEtcdSnapshotSchedule(cfg, patterns)...)
+	errors = append(errors, validateCertKey(cfg, patterns)...)
+	errors = append(errors, validateNodeLabels(cfg)...)
+	errors = append(errors, validateNodeTaints(cfg)...)
+	errors = append(errors, validateStorageBackend(cfg)...)
+	errors = append(errors, validateAirgap(cfg)...)
+
+	// The default OIDC issuer needs at least one audience, otherwise the
+	// kube-apiserver rejects the generated AuthenticationConfiguration
+	if audiences, exists := cfg["OIDC_DEFAULT_AUDIENCES"]; exists && audiences != nil {
+		nonEmpty := 0
+		for _, audience := range stringListValue(audiences) {
+			if audience != "" {
+				nonEmpty++
+			}
+		}
+		if nonEmpty == 0 {
+			errors = append(errors, "OIDC_DEFAULT_AUDIENCES must contain at least one audience (default: [\"k8s\"])")
+		}
+	}
+
+	// The OIDC kubeconfig points at the default kc.<DOMAIN> issuer
+	if auth, _ := cfg["KUBECONFIG_AUTH"].(string); auth == "oidc" {
+		if domain, _ := cfg["DOMAIN"].(string); domain == "" {
+			errors = append(errors, "KUBECONFIG_AUTH: oidc requires DOMAIN, since the kubeconfig uses the https://kc.<DOMAIN>/realms/airm issuer")
+		}
+	}
+
 	// Validate constraints (mutually exclusive, one-of, etc.)
 	constraintErrors := ValidateConstraints(cfg)
 	errors = append(errors, constraintErrors...)
@@ -229,6 +357,201 @@ func Validate(cfg Config) []string {
 	return errors
 }
 
+// validateOIDCProviders checks the ADDITIONAL_OIDC_PROVIDERS entries that are
+// templated into the kube-apiserver AuthenticationConfiguration
+func validateOIDCProviders(cfg Config) []string {
+	var errors []string
+	providers, ok := cfg["ADDITIONAL_OIDC_PROVIDERS"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	for i, item := range providers {
+		provider, ok := item.(map[string]interface{})
+		if !ok {
+			errors = append(errors, fmt.Sprintf("ADDITIONAL_OIDC_PROVIDERS[%d]: must be a map with url and audiences", i))
+			continue
+		}
+
+		issuer, _ := provider["url"].(string)
+		if !strings.HasPrefix(issuer, "https://") {
+			errors = append(errors, fmt.Sprintf("ADDITIONAL_OIDC_PROVIDERS[%d].url: must be an https:// issuer URL. Found: %q", i, issuer))
+		} else if _, _, err := oidcIssuerAddress(issuer); err != nil {
+			errors = append(errors, fmt.Sprintf("ADDITIONAL_OIDC_PROVIDERS[%d].url: %v. Found: %q", i, err, issuer))
+		}
+		if len(stringListValue(provider["audiences"])) == 0 {
+			errors = append(errors, fmt.Sprintf("ADDITIONAL_OIDC_PROVIDERS[%d].audiences: must contain at least one audience", i))
+		}
+
+		// Optional per-issuer claim mappings; the claims must be usable JWT claim names
+		for _, field := range []string{"username_claim", "groups_claim"} {
+			value, exists := provider[field]
+			if !exists || value == nil {
+				continue
+			}
+			claim, isStr := value.(string)
+			if !isStr || claim == "" || strings.ContainsAny(claim, " \t\"") {
+				errors = append(errors, fmt.Sprintf("ADDITIONAL_OIDC_PROVIDERS[%d].%s: must be a non-empty claim name without spaces or quotes (e.g. email)", i, field))
+			}
+		}
+		if value, exists := provider["prefix"]; exists && value != nil {
+			if prefix, isStr := value.(string); !isStr || strings.ContainsAny(prefix, " \t\"") {
+				errors = append(errors, fmt.Sprintf("ADDITIONAL_OIDC_PROVIDERS[%d].prefix: must be a string without spaces or quotes (e.g. \"corp:\")", i))
+			}
+		}
+
+		// Optional pin of the issuer's serving certificate, checked at deploy time
+		if value, exists := provider["expected_fingerprint"]; exists && value != nil {
+			if fingerprint, isStr := value.(string); !isStr || !sha256FingerprintPattern.MatchString(fingerprint) {
+				errors = append(errors, fmt.Sprintf("ADDITIONAL_OIDC_PROVIDERS[%d].expected_fingerprint: must be a SHA-256 fingerprint as 64 hex digits, optionally colon-separated (openssl x509 -noout -fingerprint -sha256)", i))
+			}
+		}
+
+		for key := range provider {
+			if !contains([]string{"url", "audiences", "username_claim", "groups_claim", "prefix", "expected_fingerprint"}, key) {
+				errors = append(errors, fmt.Sprintf("ADDITIONAL_OIDC_PROVIDERS[%d]: unknown field %q (valid: url, audiences, username_claim, groups_claim, prefix, expected_fingerprint)", i, key))
+			}
+		}
+	}
+	return errors
+}
+
+// oidcIssuerAddress returns the host and port an issuer URL serves its TLS
+// certificate on, defaulting to 443 when the URL has no port. The deploy-time
+// fingerprint check connects to the same address.
+func oidcIssuerAddress(issuer string) (host, port string, err error) {
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid URL")
+	}
+	if u.Hostname() == "" {
+		return "", "", fmt.Errorf("missing host")
+	}
+	port = u.Port()
+	if port == "" {
+		port = "443"
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", "", fmt.Errorf("invalid port %q", port)
+	}
+	return u.Hostname(), port, nil
+}
+
+// validateSysctls checks the SYSCTLS entries written to
+// /etc/sysctl.d/99-cluster-bloom.conf. Both a YAML map and the comma-separated
+// key=value form produced by the web UI are accepted.
+func validateSysctls(cfg Config) []string {
+	var errors []string
+	entries := make(map[string]any)
+	switch v := cfg["SYSCTLS"].(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		entries = v
+	case string:
+		for _, pair := range stringListValue(v) {
+			if pair == "" {
+				continue
+			}
+			key, value, found := strings.Cut(pair, "=")
+			if !found {
+				errors = append(errors, fmt.Sprintf("SYSCTLS: %q must be in key=value form (e.g. vm.max_map_count=262144)", pair))
+				continue
+			}
+			entries[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	default:
+		return []string{fmt.Sprintf("SYSCTLS must be a map of sysctl keys to values, got %T", v)}
+	}
+
+	for key, value := range entries {
+		if !sysctlKeyPattern.MatchString(key) {
+			errors = append(errors, fmt.Sprintf("SYSCTLS: %q is not a sysctl key (expected a dotted path such as vm.max_map_count)", key))
+			continue
+		}
+		switch val := value.(type) {
+		case int, float64:
+		case string:
+			if strings.TrimSpace(val) == "" || strings.ContainsAny(val, "\n=") {
+				errors = append(errors, fmt.Sprintf("SYSCTLS.%s: value must be a non-empty single-line value", key))
+			}
+		default:
+			errors = append(errors, fmt.Sprintf("SYSCTLS.%s: value must be a number or string, got %T", key, value))
+		}
+	}
+	sort.Strings(errors)
+	return errors
+}
+
+// validateRegistryMirrors checks the REGISTRY_MIRRORS entries written to
+// /etc/rancher/rke2/registries.yaml. A YAML map of registry to endpoint (or
+// list of endpoints), the comma-separated registry=endpoint form produced by
+// the web UI, and the path of a complete registries.yaml are accepted.
+func validateRegistryMirrors(cfg Config) []string {
+	var errors []string
+	mirrors := make(map[string][]any)
+	switch v := cfg["REGISTRY_MIRRORS"].(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		for registry, endpoints := range v {
+			if list, ok := endpoints.([]interface{}); ok {
+				mirrors[registry] = list
+			} else {
+				mirrors[registry] = []any{endpoints}
+			}
+		}
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(v), "/") {
+			path := strings.TrimSpace(v)
+			if filepath.Clean(path) != path || strings.Contains(path, ",") {
+				errors = append(errors, fmt.Sprintf("REGISTRY_MIRRORS: %q is not a clean absolute path to a registries.yaml", path))
+			}
+			if isFieldSet(cfg, "DOCKERHUB_USER") || isFieldSet(cfg, "DOCKERHUB_TOKEN") {
+				errors = append(errors, "REGISTRY_MIRRORS: DOCKERHUB_USER and DOCKERHUB_TOKEN cannot be used with a registries.yaml path; add the docker.io credentials to the file instead")
+			}
+			return errors
+		}
+		for _, pair := range stringListValue(v) {
+			if pair == "" {
+				continue
+			}
+			registry, endpoint, found := strings.Cut(pair, "=")
+			if !found {
+				errors = append(errors, fmt.Sprintf("REGISTRY_MIRRORS: %q must be in registry=endpoint form (e.g. docker.io=https://harbor.example.com)", pair))
+				continue
+			}
+			registry = strings.TrimSpace(registry)
+			mirrors[registry] = append(mirrors[registry], strings.TrimSpace(endpoint))
+		}
+	default:
+		return []string{fmt.Sprintf("REGISTRY_MIRRORS must be a map of registries to mirror endpoints or the path of a registries.yaml, got %T", v)}
+	}
+
+	for registry, endpoints := range mirrors {
+		if !registryHostPattern.MatchString(registry) {
+			errors = append(errors, fmt.Sprintf("REGISTRY_MIRRORS: %q is not a registry host (expected e.g. docker.io, registry.example.com:5000 or *)", registry))
+			continue
+		}
+		if len(endpoints) == 0 {
+			errors = append(errors, fmt.Sprintf("REGISTRY_MIRRORS.%s: at least one mirror endpoint is required", registry))
+		}
+		for _, endpoint := range endpoints {
+			str, ok := endpoint.(string)
+			if !ok {
+				errors = append(errors, fmt.Sprintf("REGISTRY_MIRRORS.%s: endpoint must be a URL string, got %T", registry, endpoint))
+				continue
+			}
+			u, err := url.Parse(str)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+				errors = append(errors, fmt.Sprintf("REGISTRY_MIRRORS.%s: endpoint %q must be an http:// or https:// URL without credentials", registry, str))
+			}
+		}
+	}
+	sort.Strings(errors)
+	return errors
+}
+
 // validateGPUStack resolves GPU_STACK_FAMILY against the compatibility matrix
 // and returns a non-empty error string for unsupported combinations. The
 // pattern type already rejects malformed values; this catches family/ROCm/
@@ -246,6 +569,27 @@ func validateGPUStack(cfg Config) string {
 	return ""
 }
 
+// stringListValue returns the items of a list-valued config field, accepting
+// either a YAML sequence or a legacy comma-separated string
+func stringListValue(value any) []string {
+	var items []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if itemStr, ok := item.(string); ok {
+				items = append(items, strings.TrimSpace(itemStr))
+			}
+		}
+	case string:
+		if v != "" {
+			for _, item := range strings.Split(v, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		}
+	}
+	return items
+}
+
 func isArgVisible(arg Argument, cfg Config) bool {
 	if arg.Dependencies == "" {
 		return true
@@ -313,3 +657,475 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+// rke2IPFamilies maps RKE2_IP_FAMILY to the number of IPv4 and IPv6 CIDRs
+// each of RKE2_CLUSTER_CIDR and RKE2_SERVICE_CIDR must contain
+var rke2IPFamilies = map[string]struct{ v4, v6 int }{
+	"ipv4":      {1, 0},
+	"ipv6":      {0, 1},
+	"dualstack": {1, 1},
+}
+
+// validateRKE2Network checks that the pod and service CIDRs match
+// RKE2_IP_FAMILY and do not overlap. Overlapping networks break
+// kube-proxy/CNI routing silently, so they are rejected before RKE2 is
+// installed. Unset CIDRs fall back to their schema defaults, which are IPv4.
+// CNI_MTU is rejected for CNIs other than Cilium, which would ignore it.
+func validateRKE2Network(cfg Config, schema []Argument, patterns map[string]*regexp.Regexp) []string {
+	var errors []string
+
+	family, _ := cfg["RKE2_IP_FAMILY"].(string)
+	if family == "" {
+		family = "ipv4"
+	}
+	want, knownFamily := rke2IPFamilies[family]
+
+	networks := make(map[string][]*net.IPNet)
+	for _, key := range []string{"RKE2_CLUSTER_CIDR", "RKE2_SERVICE_CIDR"} {
+		value, _ := cfg[key].(string)
+		if value == "" {
+			for _, arg := range schema {
+				if arg.Key == key {
+					value, _ = arg.Default.(string)
+				}
+			}
+		}
+		// Malformed values are already reported by the clusterCidr pattern
+		if pattern, ok := patterns["clusterCidr"]; value == "" || (ok && !pattern.MatchString(value)) {
+			continue
+		}
+
+		var v4, v6 int
+		valid := true
+		for _, item := range strings.Split(value, ",") {
+			_, ipNet, err := net.ParseCIDR(item)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("%s: invalid CIDR %s", key, item))
+				valid = false
+				break
+			}
+			if ipNet.IP.To4() != nil {
+				v4++
+			} else {
+				v6++
+			}
+			networks[key] = append(networks[key], ipNet)
+		}
+		if !valid || !knownFamily || (v4 == want.v4 && v6 == want.v6) {
+			continue
+		}
+
+		switch family {
+		case "dualstack":
+			errors = append(errors, fmt.Sprintf("%s must contain one IPv4 and one IPv6 CIDR separated by a comma when RKE2_IP_FAMILY is dualstack (e.g., 10.242.0.0/16,fd00:10:242::/56). Found: %s", key, value))
+		case "ipv6":
+			errors = append(errors, fmt.Sprintf("%s must be a single IPv6 CIDR when RKE2_IP_FAMILY is ipv6 (e.g., fd00:10:242::/56). Found: %s", key, value))
+		default:
+			errors = append(errors, fmt.Sprintf("%s must be a single IPv4 CIDR when RKE2_IP_FAMILY is %s. Set RKE2_IP_FAMILY to dualstack or ipv6 for IPv6 networks. Found: %s", key, family, value))
+		}
+		delete(networks, key)
+	}
+
+	for _, clusterNet := range networks["RKE2_CLUSTER_CIDR"] {
+		for _, serviceNet := range networks["RKE2_SERVICE_CIDR"] {
+			if clusterNet.Contains(serviceNet.IP) || serviceNet.Contains(clusterNet.IP) {
+				errors = append(errors, fmt.Sprintf("RKE2_CLUSTER_CIDR (%s) and RKE2_SERVICE_CIDR (%s) must not overlap", clusterNet, serviceNet))
+			}
+		}
+	}
+
+	// CNI_MTU is only written to the rke2-cilium HelmChartConfig
+	if mtu, exists := cfg["CNI_MTU"]; exists && mtu != nil && mtu != "" {
+		if cni, _ := cfg["RKE2_CNI"].(string); cni != "" && cni != "cilium" {
+			errors = append(errors, fmt.Sprintf("CNI_MTU only configures Cilium, but RKE2_CNI is %s; remove CNI_MTU or set the MTU through the %s chart values", cni, cni))
+		}
+	}
+
+	return errors
+}
+
+// ipv4Range is an inclusive range of IPv4 addresses
+type ipv4Range struct {
+	start, end uint32
+}
+
+// parseIPv4Range parses a single IP, a start-end range or a CIDR
+func parseIPv4Range(entry string) (ipv4Range, error) {
+	toUint := func(s string) (uint32, bool) {
+		ip := net.ParseIP(s).To4()
+		if ip == nil {
+			return 0, false
+		}
+		return uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3]), true
+	}
+
+	switch {
+	case strings.Contains(entry, "/"):
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil || ipNet.IP.To4() == nil {
+			return ipv4Range{}, fmt.Errorf("invalid CIDR")
+		}
+		start, _ := toUint(ipNet.IP.String())
+		ones, bits := ipNet.Mask.Size()
+		return ipv4Range{start, start | (1<<uint(bits-ones) - 1)}, nil
+	case strings.Contains(entry, "-"):
+		parts := strings.SplitN(entry, "-", 2)
+		start, startOK := toUint(parts[0])
+		end, endOK := toUint(parts[1])
+		if !startOK || !endOK {
+			return ipv4Range{}, fmt.Errorf("invalid range, expected <start IP>-<end IP>")
+		}
+		if end < start {
+			return ipv4Range{}, fmt.Errorf("range end is before its start")
+		}
+		return ipv4Range{start, end}, nil
+	default:
+		ip, ok := toUint(entry)
+		if !ok {
+			return ipv4Range{}, fmt.Errorf("invalid IPv4 address")
+		}
+		return ipv4Range{ip, ip}, nil
+	}
+}
+
+// certKeyBits lists the key sizes openssl accepts for each CERT_KEY_TYPE
+var certKeyBits = map[string][]string{
+	"rsa":   {"2048", "3072", "4096"},
+	"ecdsa": {"256", "384", "521"},
+}
+
+// validateCertKey checks that CERT_KEY_BITS is a size CERT_KEY_TYPE supports,
+// so a mismatch fails here rather than in openssl on the first node
+func validateCertKey(cfg Config, patterns map[string]*regexp.Regexp) []string {
+	keyType, _ := cfg["CERT_KEY_TYPE"].(string)
+	sizes, ok := certKeyBits[keyType]
+	bits := fmt.Sprint(cfg["CERT_KEY_BITS"])
+	if !ok || cfg["CERT_KEY_BITS"] == nil || bits == "" {
+		return nil
+	}
+	// Malformed values are already reported by the certKeyBits pattern
+	if pattern, ok := patterns["certKeyBits"]; ok && !pattern.MatchString(bits) {
+		return nil
+	}
+	if !contains(sizes, bits) {
+		return []string{fmt.Sprintf("CERT_KEY_BITS %s is not a valid %s key size. Valid sizes: %s", bits, keyType, strings.Join(sizes, ", "))}
+	}
+	return nil
+}
+
+// validateStorageBackend rejects disk settings that STORAGE_BACKEND would
+// silently ignore: disks with "none", or an explicit provisioner with
+// NO_DISKS_FOR_CLUSTER, which skips storage setup entirely
+func validateStorageBackend(cfg Config) []string {
+	backend, _ := cfg["STORAGE_BACKEND"].(string)
+	switch backend {
+	case "":
+		return nil
+	case "none":
+		var errors []string
+		for _, key := range []string{"CLUSTER_DISKS", "CLUSTER_PREMOUNTED_DISKS"} {
+			if len(stringListValue(cfg[key])) > 0 {
+				errors = append(errors, fmt.Sprintf("%s is set but STORAGE_BACKEND is none, which skips all disk preparation; remove %s or choose longhorn or local-path", key, key))
+			}
+		}
+		return errors
+	}
+	if isFieldSet(cfg, "NO_DISKS_FOR_CLUSTER") {
+		return []string{fmt.Sprintf("STORAGE_BACKEND %s needs cluster disks but NO_DISKS_FOR_CLUSTER is true; use STORAGE_BACKEND: none to skip storage setup", backend)}
+	}
+	return nil
+}
+
+// validateAirgap rejects a ClusterForge release AIRGAP would have to download.
+// The artifact files themselves are checked on the node by validate_node.
+func validateAirgap(cfg Config) []string {
+	if !isFieldSet(cfg, "AIRGAP") || !isFieldSet(cfg, "FIRST_NODE") {
+		return nil
+	}
+	release, _ := cfg["CLUSTERFORGE_RELEASE"].(string)
+	if release == "" || release == "none" || strings.HasPrefix(release, "/") {
+		return nil
+	}
+	return []string{fmt.Sprintf("CLUSTERFORGE_RELEASE %q needs internet access, but AIRGAP is true; use none, empty or the absolute path of a local ClusterForge release tarball", release)}
+}
+
+var (
+	// labelNamePattern is a Kubernetes label name, or a non-empty label value
+	labelNamePattern = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+	// labelPrefixPattern is the DNS subdomain prefix of a label key
+	labelPrefixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	// taintEffects are the effects RKE2 node-taint accepts
+	taintEffects = []string{"NoSchedule", "PreferNoSchedule", "NoExecute"}
+)
+
+// validateLabelKey checks a label or taint key ([prefix/]name) and returns a
+// description of the problem, or "" if the key is valid
+func validateLabelKey(key string) string {
+	if key == "" {
+		return "empty key"
+	}
+	name := key
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		if prefix == "" || len(prefix) > 253 || !labelPrefixPattern.MatchString(prefix) {
+			return fmt.Sprintf("invalid key prefix %q, must be a DNS subdomain", prefix)
+		}
+	}
+	if !labelNamePattern.MatchString(name) {
+		return fmt.Sprintf("invalid key name %q, must be at most 63 alphanumeric characters, '-', '_' or '.'", name)
+	}
+	return ""
+}
+
+// validateLabelValue checks a label or taint value, which may be empty
+func validateLabelValue(value string) string {
+	if value != "" && !labelNamePattern.MatchString(value) {
+		return fmt.Sprintf("invalid value %q, must be at most 63 alphanumeric characters, '-', '_' or '.'", value)
+	}
+	return ""
+}
+
+// validateNodeLabels checks NODE_LABELS entries are key=value labels the
+// kubelet accepts. The kubelet refuses to start with labels in the
+// kubernetes.io namespaces, and cluster-bloom/ labels are set by bloom itself.
+func validateNodeLabels(cfg Config) []string {
+	var errors []string
+	for i, entry := range stringListValue(cfg["NODE_LABELS"]) {
+		if entry == "" {
+			continue
+		}
+		key, value, found := strings.Cut(entry, "=")
+		problem := ""
+		switch {
+		case !found:
+			problem = "expected key=value"
+		case strings.HasPrefix(key, "cluster-bloom/"):
+			problem = "cluster-bloom/ labels are managed by bloom"
+		case isKubernetesReservedLabel(key):
+			problem = "the kubelet does not allow node labels in the kubernetes.io and k8s.io namespaces except node.kubernetes.io/ and kubelet.kubernetes.io/"
+		default:
+			if problem = validateLabelKey(key); problem == "" {
+				problem = validateLabelValue(value)
+			}
+		}
+		if problem != "" {
+			errors = append(errors, fmt.Sprintf("NODE_LABELS[%d]: %s: %s", i, problem, entry))
+		}
+	}
+	return errors
+}
+
+// isKubernetesReservedLabel reports whether key is in a kubernetes.io or
+// k8s.io namespace the kubelet refuses to self-assign
+func isKubernetesReservedLabel(key string) bool {
+	i := strings.LastIndex(key, "/")
+	if i < 0 {
+		return false
+	}
+	prefix := key[:i]
+	if prefix == "node.kubernetes.io" || prefix == "kubelet.kubernetes.io" ||
+		strings.HasSuffix(prefix, ".node.kubernetes.io") || strings.HasSuffix(prefix, ".kubelet.kubernetes.io") {
+		return false
+	}
+	for _, reserved := range []string{"kubernetes.io", "k8s.io"} {
+		if prefix == reserved || strings.HasSuffix(prefix, "."+reserved) {
+			return true
+		}
+	}
+	return false
+}
+
+// extraConfigTaintPattern finds a top-level node-taint key in RKE2_EXTRA_CONFIG
+var extraConfigTaintPattern = regexp.MustCompile(`(?m)^node-taint\s*:`)
+
+// validateNodeTaints checks NODE_TAINTS entries are key[=value]:Effect taints
+// and that RKE2_EXTRA_CONFIG does not set node-taint as well, which would
+// duplicate the key in the RKE2 config.yaml
+func validateNodeTaints(cfg Config) []string {
+	var errors []string
+	taints := stringListValue(cfg["NODE_TAINTS"])
+	if extra, ok := cfg["RKE2_EXTRA_CONFIG"].(string); ok && len(taints) > 0 && extraConfigTaintPattern.MatchString(extra) {
+		errors = append(errors, "NODE_TAINTS and a node-taint entry in RKE2_EXTRA_CONFIG cannot be combined; move the RKE2_EXTRA_CONFIG taints to NODE_TAINTS")
+	}
+	for i, entry := range taints {
+		if entry == "" {
+			continue
+		}
+		problem := ""
+		sep := strings.LastIndex(entry, ":")
+		if sep < 0 {
+			problem = "expected key=value:Effect or key:Effect"
+		} else if effect := entry[sep+1:]; !contains(taintEffects, effect) {
+			problem = fmt.Sprintf("invalid effect %q, must be one of: %s", effect, strings.Join(taintEffects, ", "))
+		} else {
+			key, value, _ := strings.Cut(entry[:sep], "=")
+			if problem = validateLabelKey(key); problem == "" {
+				problem = validateLabelValue(value)
+			}
+		}
+		if problem != "" {
+			errors = append(errors, fmt.Sprintf("NODE_TAINTS[%d]: %s: %s", i, problem, entry))
+		}
+	}
+	return errors
+}
+
+// validateMetalLBRange checks that every METALLB_IP_RANGE entry is a valid IP,
+// range or CIDR and that no two entries overlap, since MetalLB rejects an
+// IPAddressPool with overlapping addresses after the cluster is up
+func validateMetalLBRange(cfg Config, patterns map[string]*regexp.Regexp) []string {
+	// Malformed strings are already reported by the ipRangeList pattern
+	if value, isString := cfg["METALLB_IP_RANGE"].(string); isString {
+		if pattern, ok := patterns["ipRangeList"]; ok && !pattern.MatchString(value) {
+			return nil
+		}
+	}
+
+	var errors []string
+	var ranges []ipv4Range
+	var entries []string
+	for i, entry := range stringListValue(cfg["METALLB_IP_RANGE"]) {
+		if entry == "" {
+			continue
+		}
+		r, err := parseIPv4Range(entry)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("METALLB_IP_RANGE[%d]: %v: %s", i, err, entry))
+			continue
+		}
+		for j, other := range ranges {
+			if r.start <= other.end && other.start <= r.end {
+				errors = append(errors, fmt.Sprintf("METALLB_IP_RANGE: %s overlaps %s", entry, entries[j]))
+			}
+		}
+		ranges = append(ranges, r)
+		entries = append(entries, entry)
+	}
+	return errors
+}
+
+// cronFieldRanges are the allowed values of the five cron fields; day of
+// week accepts both 0 and 7 for Sunday
+var cronFieldRanges = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// validateServerIPs requires SERVER_IP or SERVER_IPS on additional nodes and
+// checks that every SERVER_IPS entry is an IPv4 address
+func validateServerIPs(cfg Config, patterns map[string]*regexp.Regexp) []string {
+	if !evaluateDependency("FIRST_NODE=false", cfg) {
+		return nil
+	}
+	var errors []string
+	servers := 0
+	for i, server := range stringListValue(cfg["SERVER_IPS"]) {
+		if server == "" {
+			continue
+		}
+		servers++
+		if pattern, ok := patterns["ipv4"]; ok && !pattern.MatchString(server) {
+			errors = append(errors, fmt.Sprintf("SERVER_IPS[%d]: must be an IPv4 address. Found: %s", i, server))
+		}
+	}
+	if serverIP, _ := cfg["SERVER_IP"].(string); servers == 0 && serverIP == "" {
+		errors = append(errors, "SERVER_IP is required (or SERVER_IPS, to try several servers in order)")
+	}
+	return errors
+}
+
+// validateJoinToken requires JOIN_TOKEN or JOIN_TOKEN_FILE on additional
+// nodes. The token file itself is checked on the node by validate_node.
+func validateJoinToken(cfg Config) []string {
+	if !evaluateDependency("FIRST_NODE=false", cfg) {
+		return nil
+	}
+	token, _ := cfg["JOIN_TOKEN"].(string)
+	tokenFile, _ := cfg["JOIN_TOKEN_FILE"].(string)
+	if token == "" && tokenFile == "" {
+		return []string{"JOIN_TOKEN is required (or JOIN_TOKEN_FILE, to read it from a file on the node)"}
+	}
+	return nil
+}
+
+// rke2DataDirSystemDirs are directories RKE2_DATA_DIR may not be or sit under
+var rke2DataDirSystemDirs = []string{"/bin", "/boot", "/dev", "/etc", "/lib", "/lib64", "/proc", "/root", "/run", "/sbin", "/sys", "/usr"}
+
+// rke2DataDirSharedDirs hold home directories, mount points and system data,
+// so none of their direct children may be RKE2_DATA_DIR either
+var rke2DataDirSharedDirs = []string{"/home", "/media", "/mnt", "/var"}
+
+// validateRKE2DataDir keeps RKE2_DATA_DIR on a dedicated directory, since
+// bloom cleanup deletes RKE2's data inside it. The dataDirPath pattern accepts
+// /var, /mnt/disk0 and /var/../usr.
+func validateRKE2DataDir(cfg Config) []string {
+	dataDir, _ := cfg["RKE2_DATA_DIR"].(string)
+	if dataDir == "" || !strings.HasPrefix(dataDir, "/") {
+		return nil
+	}
+	if filepath.Clean(dataDir) != dataDir {
+		return []string{fmt.Sprintf("RKE2_DATA_DIR %q must not contain '.' or '..' segments", dataDir)}
+	}
+	parent := filepath.Dir(dataDir)
+	if parent == "/" || contains(rke2DataDirSharedDirs, parent) {
+		return []string{fmt.Sprintf("RKE2_DATA_DIR %q must be a dedicated directory such as /data/rke2, not a top-level directory, home directory or mount point", dataDir)}
+	}
+	for _, dir := range rke2DataDirSystemDirs {
+		if strings.HasPrefix(dataDir, dir+"/") {
+			return []string{fmt.Sprintf("RKE2_DATA_DIR %q must not be inside the system directory %s", dataDir, dir)}
+		}
+	}
+	return nil
+}
+
+// validateEtcdSnapshotSchedule checks the values of ETCD_SNAPSHOT_SCHEDULE
+// are in range. The cronSchedule pattern only checks its shape.
+func validateEtcdSnapshotSchedule(cfg Config, patterns map[string]*regexp.Regexp) []string {
+	schedule, _ := cfg["ETCD_SNAPSHOT_SCHEDULE"].(string)
+	if pattern, ok := patterns["cronSchedule"]; schedule == "" || !ok || !pattern.MatchString(schedule) {
+		return nil
+	}
+	var errors []string
+	for i, field := range strings.Fields(schedule) {
+		if err := checkCronField(field, cronFieldRanges[i].min, cronFieldRanges[i].max); err != "" {
+			errors = append(errors, fmt.Sprintf("ETCD_SNAPSHOT_SCHEDULE: %s field %q %s", cronFieldRanges[i].name, field, err))
+		}
+	}
+	return errors
+}
+
+// checkCronField checks each comma-separated item of a cron field (*, n, a-b,
+// each optionally followed by /step) and returns the problem, or ""
+func checkCronField(field string, min, max int) string {
+	for _, item := range strings.Split(field, ",") {
+		span, step, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			if n, err := strconv.Atoi(step); err != nil || n < 1 {
+				return fmt.Sprintf("has an invalid step %q", step)
+			}
+		}
+		if span == "*" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(span, "-")
+		if !isRange {
+			hi = lo
+		}
+		start, errStart := strconv.Atoi(lo)
+		end, errEnd := strconv.Atoi(hi)
+		if errStart != nil || errEnd != nil {
+			return fmt.Sprintf("has an invalid value %q", span)
+		}
+		if start < min || end > max || start > end {
+			return fmt.Sprintf("must be within %d-%d", min, max)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gpuSteps are the steps in ENABLED_STEPS/DISABLED_STEPS that only do work on
+// GPU nodes: the prepare_node tasks guarded by GPU_NODE and the playbook tags
+// they carry
+var gpuSteps = []string{"Setup and Check ROCm", "Update Modprobe (GPU nodes)", "gpu", "rocm"}
+
+// Warnings returns non-fatal notices about contradictory but valid configuration
+func Warnings(cfg Config) []string {
+	return validateGPUStepConflicts(cfg)
+}
+
+// validateGPUStepConflicts flags GPU steps enabled on a non-GPU node and GPU
+// steps disabled on a GPU node. Neither step list is honoured yet, so the
+// warnings describe what the lists will do once they are.
+func validateGPUStepConflicts(cfg Config) []string {
+	var warnings []string
+	gpuNode := isFieldSet(cfg, "GPU_NODE")
+
+	if enabled := listedGPUSteps(cfg, "ENABLED_STEPS"); len(enabled) > 0 && !gpuNode {
+		warnings = append(warnings, fmt.Sprintf(
+			"ENABLED_STEPS includes %s but GPU_NODE is false. Step lists are not yet honoured, but once they are these steps will be skipped. Set GPU_NODE: true on GPU nodes, or remove them from ENABLED_STEPS",
+			strings.Join(enabled, ", ")))
+	}
+
+	if disabled := listedGPUSteps(cfg, "DISABLED_STEPS"); len(disabled) > 0 && gpuNode {
+		warnings = append(warnings, fmt.Sprintf(
+			"DISABLED_STEPS includes %s but GPU_NODE is true. Step lists are not yet honoured, but once they are ROCm and GPU device setup will not run. Remove them from DISABLED_STEPS, or set GPU_NODE: false for a CPU-only node",
+			strings.Join(disabled, ", ")))
+	}
+
+	return warnings
+}
+
+// listedGPUSteps returns the GPU steps listed (case-insensitive) in a
+// comma-separated step list field
+func listedGPUSteps(cfg Config, key string) []string {
+	var found []string
+	for _, step := range stringListValue(cfg[key]) {
+		for _, gpuStep := range gpuSteps {
+			if strings.EqualFold(strings.TrimSpace(step), gpuStep) && !contains(found, gpuStep) {
+				found = append(found, gpuStep)
+			}
+		}
+	}
+	return found
+}
@@ -21,6 +21,7 @@ import (
 	"crypto/rand"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -42,11 +43,20 @@ type EphemeralSSHManager struct {
 	PublicKeyPath        string // {workdir}/ssh/id_ephemeral.pub
 	AuthorizedKeysPath   string // /home/{username}/.ssh/authorized_keys
 	AuthorizedKeysBackup string // {workdir}/ssh/authorized_keys.backup
+	Reuse                bool   // Reuse the key of an interrupted run instead of failing
 	isInstalled          bool   // Track installation state for cleanup
 }
 
-// NewEphemeralSSHManager creates a new ephemeral SSH key manager for single-node deployment
-func NewEphemeralSSHManager(workDir, username string) (*EphemeralSSHManager, error) {
+// ErrKeyExists is returned by Setup when authorized_keys still holds a bloom
+// key from an earlier run that cannot be reused
+var ErrKeyExists = errors.New("bloom ephemeral key already exists in authorized_keys")
+
+// bloomKeyMarkers identify the authorized_keys lines bloom added
+var bloomKeyMarkers = []string{"# bloom-ephemeral-key", "bloom-ephemeral@localhost"}
+
+// NewEphemeralSSHManager creates a new ephemeral SSH key manager for single-node deployment.
+// With reuse set, Setup picks up the key and backup left behind by an interrupted run.
+func NewEphemeralSSHManager(workDir, username string, reuse bool) (*EphemeralSSHManager, error) {
 	sshDir := filepath.Join(workDir, "ssh")
 
 	// Get the user's actual home directory
@@ -67,9 +77,11 @@ func NewEphemeralSSHManager(workDir, username string) (*EphemeralSSHManager, err
 		PublicKeyPath:        filepath.Join(sshDir, "id_ephemeral.pub"),
 		AuthorizedKeysPath:   filepath.Join(userSSHDir, "authorized_keys"),
 		AuthorizedKeysBackup: authKeysBackupPath,
+		Reuse:                reuse,
 		isInstalled:          false,
 	}, nil
 }
+
 func getUserSSHDir(username string) (string, error) {
 	// Look up the actual user to get their home directory
 	// Don't rely on HOME env var as it may point to /root when using sudo
@@ -82,6 +94,15 @@ func getUserSSHDir(username string) (string, error) {
 
 // Setup generates ephemeral SSH keys and installs the public key for localhost access
 func (e *EphemeralSSHManager) Setup() error {
+	if e.Reuse {
+		if backup, ok := e.findReusableKey(); ok {
+			fmt.Printf("   ♻️  Reusing the ephemeral SSH key of a previous run (backup: %s)\n", backup)
+			e.AuthorizedKeysBackup = backup
+			e.isInstalled = true
+			return nil
+		}
+	}
+
 	// Generate ephemeral key pair
 	if err := e.generateKey(); err != nil {
 		return fmt.Errorf("failed to generate ephemeral key: %w", err)
@@ -110,6 +131,175 @@ func (e *EphemeralSSHManager) Cleanup() error {
 	return nil
 }
 
+// ForceCleanup removes every bloom key line from authorized_keys and deletes
+// the ephemeral key files, whether or not this manager installed the key.
+// Unlike Cleanup it keeps the user's current authorized_keys instead of
+// restoring a backup, so keys added since the interrupted run survive.
+func (e *EphemeralSSHManager) ForceCleanup() error {
+	err := e.runAsUser(func() error {
+		content, err := os.ReadFile(e.AuthorizedKeysPath)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read authorized_keys: %w", err)
+		}
+
+		cleaned, removed := stripBloomKeys(string(content))
+		if len(removed) == 0 {
+			return nil
+		}
+		if err := e.replaceAuthorizedKeys([]byte(cleaned)); err != nil {
+			return err
+		}
+		fmt.Printf("   Removed %d bloom key line(s) from %s\n", len(removed), e.AuthorizedKeysPath)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove SSH key for user %s: %w", e.Username, err)
+	}
+
+	e.isInstalled = false
+	return e.removeKeyFiles()
+}
+
+// StaleKeys returns the bloom key lines in authorized_keys and the backup
+// RemoveStaleKeys would restore, without changing anything
+func (e *EphemeralSSHManager) StaleKeys() ([]string, string, error) {
+	content, err := os.ReadFile(e.AuthorizedKeysPath)
+	if os.IsNotExist(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read authorized_keys: %w", err)
+	}
+	_, stale := stripBloomKeys(string(content))
+	if len(stale) == 0 {
+		return nil, "", nil
+	}
+	return stale, e.newestCleanBackup(), nil
+}
+
+// RemoveStaleKeys removes the bloom key lines an interrupted run left in
+// authorized_keys, along with the run's key files. The newest backup without
+// bloom keys is restored when there is one; otherwise only the bloom lines are
+// dropped. It returns the removed lines and the restored backup, if any.
+func (e *EphemeralSSHManager) RemoveStaleKeys() ([]string, string, error) {
+	var removed []string
+	var restoredFrom string
+	err := e.runAsUser(func() error {
+		content, err := os.ReadFile(e.AuthorizedKeysPath)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read authorized_keys: %w", err)
+		}
+
+		var cleaned string
+		cleaned, removed = stripBloomKeys(string(content))
+		if len(removed) == 0 {
+			return nil
+		}
+		if backup := e.newestCleanBackup(); backup != "" {
+			backupContent, err := os.ReadFile(backup)
+			if err != nil {
+				return fmt.Errorf("failed to read backup %s: %w", backup, err)
+			}
+			cleaned = string(backupContent)
+			restoredFrom = backup
+		}
+		return e.replaceAuthorizedKeys([]byte(cleaned))
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to remove SSH key for user %s: %w", e.Username, err)
+	}
+
+	e.isInstalled = false
+	return removed, restoredFrom, e.removeKeyFiles()
+}
+
+// replaceAuthorizedKeys overwrites authorized_keys with content through a
+// temporary file, keeping its ownership and permissions
+func (e *EphemeralSSHManager) replaceAuthorizedKeys(content []byte) error {
+	uid, gid, mode, err := getFileInfo(e.AuthorizedKeysPath)
+	if err != nil {
+		return fmt.Errorf("failed to get original file info: %w", err)
+	}
+	tmpPath := e.AuthorizedKeysPath + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0600); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	defer os.Remove(tmpPath)
+	if err := safelyOverwriteFile(tmpPath, e.AuthorizedKeysPath, uid, gid, mode); err != nil {
+		return fmt.Errorf("failed to overwrite original file: %w", err)
+	}
+	return nil
+}
+
+// stripBloomKeys drops the bloom key lines from authorized_keys content, along
+// with the blank line installPublicKey puts in front of each, and returns the
+// dropped key lines
+func stripBloomKeys(content string) (string, []string) {
+	lines := strings.SplitAfter(content, "\n")
+	kept := make([]string, 0, len(lines))
+	var removed []string
+	for _, line := range lines {
+		if !hasBloomMarker(line) {
+			kept = append(kept, line)
+			continue
+		}
+		removed = append(removed, strings.TrimSpace(line))
+		if n := len(kept); n > 0 && strings.TrimSpace(kept[n-1]) == "" {
+			kept = kept[:n-1]
+		}
+	}
+	return strings.Join(kept, ""), removed
+}
+
+func hasBloomMarker(line string) bool {
+	for _, marker := range bloomKeyMarkers {
+		if strings.Contains(line, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// findReusableKey reports whether an interrupted run left a usable key behind:
+// both key files, the public key installed in authorized_keys, and a backup
+// without bloom keys to restore on cleanup. It returns the newest such backup.
+func (e *EphemeralSSHManager) findReusableKey() (string, bool) {
+	if _, err := os.Stat(e.PrivateKeyPath); err != nil {
+		return "", false
+	}
+	pubKey, err := os.ReadFile(e.PublicKeyPath)
+	if err != nil || len(strings.TrimSpace(string(pubKey))) == 0 {
+		return "", false
+	}
+	authKeys, err := os.ReadFile(e.AuthorizedKeysPath)
+	if err != nil || !strings.Contains(string(authKeys), strings.TrimSpace(string(pubKey))) {
+		return "", false
+	}
+
+	backup := e.newestCleanBackup()
+	return backup, backup != ""
+}
+
+// newestCleanBackup returns the newest authorized_keys backup that holds no
+// bloom key, or "" when there is none
+func (e *EphemeralSSHManager) newestCleanBackup() string {
+	// Timestamped names sort chronologically; try the newest first
+	backups, _ := filepath.Glob(e.AuthorizedKeysPath + ".backup.*")
+	for i := len(backups) - 1; i >= 0; i-- {
+		content, err := os.ReadFile(backups[i])
+		if err == nil && !hasBloomMarker(string(content)) {
+			return backups[i]
+		}
+	}
+	return ""
+}
+
 // verifyBackup checks if the backup file exists and is readable
 func (e *EphemeralSSHManager) verifyBackup() bool {
 	if stat, err := os.Stat(e.AuthorizedKeysBackup); err != nil {
@@ -224,12 +414,11 @@ func (e *EphemeralSSHManager) validateAuthorizedKeys() error {
 	}
 
 	// Check for either bloom marker (comment or hostname)
-	hasBloomComment := strings.Contains(string(authKeysContent), "# bloom-ephemeral-key")
-	hasBloomHostname := strings.Contains(string(authKeysContent), "bloom-ephemeral@localhost")
-
-	if hasBloomComment || hasBloomHostname {
+	if hasBloomMarker(string(authKeysContent)) {
 		fmt.Printf("❌ ERROR: Bloom ephemeral key already exists in authorized_keys\n")
 		fmt.Printf("   This indicates a previous bloom run was not cleaned up properly.\n")
+		fmt.Printf("   QUICKEST: Run 'sudo bloom ssh-cleanup' to remove the stale key, or re-run\n")
+		fmt.Printf("   bloom with --reuse-ssh-key to reuse or replace it\n")
 		fmt.Printf("   RECOMMENDED: Restore from backup (if available):\n")
 		fmt.Printf("   1. Check for backup files: ls %s*.backup.*\n", e.AuthorizedKeysPath)
 		fmt.Printf("   2. Restore the most recent backup:\n")
@@ -244,7 +433,7 @@ func (e *EphemeralSSHManager) validateAuthorizedKeys() error {
 		fmt.Printf("      ssh-ed25519 AAAAC3NzaC... bloom-ephemeral@localhost # bloom-ephemeral-key\n")
 		fmt.Printf("   4. Delete the entire line(s) with bloom markers\n")
 		fmt.Printf("   5. Save and re-run bloom\n")
-		return fmt.Errorf("bloom ephemeral key already exists in authorized_keys")
+		return ErrKeyExists
 	}
 
 	return nil
@@ -0,0 +1,168 @@
+package ssh
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+)
+
+func TestStripBloomKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		want        string
+		wantRemoved int
+	}{
+		{
+			name:    "no bloom key",
+			content: "ssh-ed25519 AAAA user@host\n",
+			want:    "ssh-ed25519 AAAA user@host\n",
+		},
+		{
+			name:        "key appended by installPublicKey",
+			content:     "ssh-ed25519 AAAA user@host\n\nssh-ed25519 BBBB bloom-ephemeral@localhost # bloom-ephemeral-key\n",
+			want:        "ssh-ed25519 AAAA user@host\n",
+			wantRemoved: 1,
+		},
+		{
+			name:        "keys from two interrupted runs",
+			content:     "ssh-ed25519 AAAA user@host\n\nssh-ed25519 BBBB bloom-ephemeral@localhost # bloom-ephemeral-key\n\nssh-ed25519 CCCC bloom-ephemeral@localhost # bloom-ephemeral-key\nssh-rsa DDDD other@host\n",
+			want:        "ssh-ed25519 AAAA user@host\nssh-rsa DDDD other@host\n",
+			wantRemoved: 2,
+		},
+		{
+			name:        "hostname marker only",
+			content:     "ssh-ed25519 BBBB bloom-ephemeral@localhost",
+			want:        "",
+			wantRemoved: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, removed := stripBloomKeys(tt.content)
+			if got != tt.want || len(removed) != tt.wantRemoved {
+				t.Errorf("stripBloomKeys() = %q, %d; want %q, %d", got, len(removed), tt.want, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestFindReusableKey(t *testing.T) {
+	const pubKey = "ssh-ed25519 BBBB bloom-ephemeral@localhost"
+
+	setup := func(t *testing.T) *EphemeralSSHManager {
+		dir := t.TempDir()
+		e := &EphemeralSSHManager{
+			PrivateKeyPath:     filepath.Join(dir, "id_ephemeral"),
+			PublicKeyPath:      filepath.Join(dir, "id_ephemeral.pub"),
+			AuthorizedKeysPath: filepath.Join(dir, "authorized_keys"),
+		}
+		write := func(path, content string) {
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		write(e.PrivateKeyPath, "private")
+		write(e.PublicKeyPath, pubKey)
+		write(e.AuthorizedKeysPath, "ssh-ed25519 AAAA user@host\n\n"+pubKey+" # bloom-ephemeral-key\n")
+		write(e.AuthorizedKeysPath+".backup.20250101_100000", "ssh-ed25519 AAAA user@host\n")
+		// A later backup taken while a bloom key was installed must be skipped
+		write(e.AuthorizedKeysPath+".backup.20250102_100000", pubKey+" # bloom-ephemeral-key\n")
+		return e
+	}
+
+	t.Run("reusable", func(t *testing.T) {
+		e := setup(t)
+		backup, ok := e.findReusableKey()
+		if !ok || filepath.Base(backup) != "authorized_keys.backup.20250101_100000" {
+			t.Errorf("findReusableKey() = %q, %v; want the clean backup", backup, ok)
+		}
+	})
+
+	t.Run("private key missing", func(t *testing.T) {
+		e := setup(t)
+		os.Remove(e.PrivateKeyPath)
+		if _, ok := e.findReusableKey(); ok {
+			t.Error("expected no reusable key without the private key")
+		}
+	})
+
+	t.Run("key not installed", func(t *testing.T) {
+		e := setup(t)
+		os.WriteFile(e.AuthorizedKeysPath, []byte("ssh-ed25519 AAAA user@host\n"), 0600)
+		if _, ok := e.findReusableKey(); ok {
+			t.Error("expected no reusable key when authorized_keys lacks it")
+		}
+	})
+
+	t.Run("no clean backup", func(t *testing.T) {
+		e := setup(t)
+		os.Remove(e.AuthorizedKeysPath + ".backup.20250101_100000")
+		if _, ok := e.findReusableKey(); ok {
+			t.Error("expected no reusable key without a clean backup")
+		}
+	})
+}
+
+func TestRemoveStaleKeys(t *testing.T) {
+	current, err := user.Current()
+	if err != nil {
+		t.Skip("cannot look up the current user")
+	}
+	const bloomLine = "ssh-ed25519 BBBB bloom-ephemeral@localhost # bloom-ephemeral-key"
+
+	setup := func(t *testing.T) *EphemeralSSHManager {
+		dir := t.TempDir()
+		e := &EphemeralSSHManager{
+			Username:           current.Username,
+			PrivateKeyPath:     filepath.Join(dir, "ssh", "id_ephemeral"),
+			PublicKeyPath:      filepath.Join(dir, "ssh", "id_ephemeral.pub"),
+			AuthorizedKeysPath: filepath.Join(dir, "authorized_keys"),
+		}
+		os.WriteFile(e.AuthorizedKeysPath, []byte("ssh-ed25519 AAAA user@host\nssh-rsa NEW added@later\n\n"+bloomLine+"\n"), 0600)
+		return e
+	}
+
+	t.Run("restores the newest clean backup", func(t *testing.T) {
+		e := setup(t)
+		os.WriteFile(e.AuthorizedKeysPath+".backup.20250101_100000", []byte("ssh-ed25519 AAAA user@host\n"), 0600)
+
+		removed, restoredFrom, err := e.RemoveStaleKeys()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(removed) != 1 || removed[0] != bloomLine || restoredFrom != e.AuthorizedKeysPath+".backup.20250101_100000" {
+			t.Errorf("RemoveStaleKeys() = %v, %q", removed, restoredFrom)
+		}
+		if got, _ := os.ReadFile(e.AuthorizedKeysPath); string(got) != "ssh-ed25519 AAAA user@host\n" {
+			t.Errorf("authorized_keys = %q, want the backup", got)
+		}
+	})
+
+	t.Run("strips bloom lines without a backup", func(t *testing.T) {
+		e := setup(t)
+
+		removed, restoredFrom, err := e.RemoveStaleKeys()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(removed) != 1 || restoredFrom != "" {
+			t.Errorf("RemoveStaleKeys() = %v, %q", removed, restoredFrom)
+		}
+		if got, _ := os.ReadFile(e.AuthorizedKeysPath); string(got) != "ssh-ed25519 AAAA user@host\nssh-rsa NEW added@later\n" {
+			t.Errorf("authorized_keys = %q", got)
+		}
+	})
+
+	t.Run("nothing to remove", func(t *testing.T) {
+		e := setup(t)
+		os.WriteFile(e.AuthorizedKeysPath, []byte("ssh-ed25519 AAAA user@host\n"), 0600)
+
+		removed, _, err := e.RemoveStaleKeys()
+		if err != nil || len(removed) != 0 {
+			t.Errorf("RemoveStaleKeys() = %v, %v; want nothing removed", removed, err)
+		}
+	})
+}
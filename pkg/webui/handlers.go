@@ -2,12 +2,31 @@ package webui
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/silogen/cluster-bloom/pkg/config"
 )
 
+// saveMu serializes config writes, so concurrent save and install requests
+// never interleave on the same file or start two installations
+var saveMu sync.Mutex
+
+// maxImportSize caps the size of a bloom.yaml pasted into the wizard
+const maxImportSize = 1 << 20
+
+// lastYAML is the config last generated or saved in this session, served by
+// /api/config/download; guarded by saveMu
+var lastYAML string
+
 func handleSchema(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -41,7 +60,8 @@ func handleGenerate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate before generating
+	// Normalize and validate before generating
+	config.Normalize(req.Config)
 	errors := config.Validate(req.Config)
 	if len(errors) > 0 {
 		w.WriteHeader(http.StatusBadRequest)
@@ -53,6 +73,9 @@ func handleGenerate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	yaml := config.GenerateYAML(req.Config)
+	saveMu.Lock()
+	lastYAML = yaml
+	saveMu.Unlock()
 
 	response := config.GenerateResponse{
 		YAML: yaml,
@@ -68,19 +91,228 @@ func handleSave(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req config.SaveRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := checkSameOriginJSON(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	saveMu.Lock()
+	defer saveMu.Unlock()
+
+	path, yaml, ok := saveConfig(w, r)
+	if !ok {
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"path":    path,
+		"yaml":    yaml,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleDownload serves the config last generated or saved in this session as
+// a bloom.yaml attachment, so a browser on the other end of a tunnel gets the
+// same bytes Save File writes on the node
+func handleDownload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	saveMu.Lock()
+	yaml := lastYAML
+	saveMu.Unlock()
+	if yaml == "" {
+		http.Error(w, "No config generated yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Content-Disposition", `attachment; filename="bloom.yaml"`)
+	io.WriteString(w, yaml)
+}
+
+// handleImport parses a pasted bloom.yaml and returns its values in the shape
+// the wizard's fields use, so an existing config can be edited without
+// restarting bloom. Keys the schema does not know are listed separately.
+func handleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	cfg, err := config.ParseYAML(data)
+	if err != nil {
+		http.Error(w, "Invalid YAML: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	config.Normalize(cfg)
 
-	// Validate filename
-	if req.Filename == "" {
-		http.Error(w, "Filename is required", http.StatusBadRequest)
+	values, unknown := formValues(cfg, config.Schema())
+	response := map[string]interface{}{
+		"config":  values,
+		"unknown": unknown,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// formValues converts config values to what the form fields hold: booleans
+// for checkboxes, lists for array fields, "k=v" pairs for maps and strings for
+// everything else. It also returns the keys the schema does not define.
+func formValues(cfg config.Config, schema []config.Argument) (map[string]any, []string) {
+	types := make(map[string]string, len(schema))
+	for _, arg := range schema {
+		types[arg.Key] = arg.Type
+	}
+
+	values := make(map[string]any)
+	unknown := []string{}
+	for key, value := range cfg {
+		argType, ok := types[key]
+		if !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		values[key] = formValue(argType, value)
+	}
+	sort.Strings(unknown)
+	return values, unknown
+}
+
+func formValue(argType string, value any) any {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case bool:
+		if argType == "bool" {
+			return v
+		}
+	case string:
+		if argType == "bool" && (v == "true" || v == "false") {
+			return v == "true"
+		}
+		return v
+	case []any:
+		if argType == "array" {
+			return v
+		}
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, k := range keys {
+			pairs[i] = fmt.Sprintf("%s=%v", k, v[k])
+		}
+		return strings.Join(pairs, ", ")
+	}
+	return fmt.Sprint(value)
+}
+
+// handleInstall saves the config like handleSave and then hands it to the
+// installer, which runs once the web server has shut down. It is refused when
+// bloom is not root or a non-loopback server has no auth token.
+func (s *Server) handleInstall(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Validate before saving
+	if reason := s.installRefusal(); reason != "" {
+		http.Error(w, reason, http.StatusForbidden)
+		return
+	}
+
+	if err := checkSameOriginJSON(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	saveMu.Lock()
+	defer saveMu.Unlock()
+
+	if s.installRequested != "" {
+		http.Error(w, "Installation already started with "+s.installRequested, http.StatusConflict)
+		return
+	}
+
+	path, yaml, ok := saveConfig(w, r)
+	if !ok {
+		return
+	}
+	s.installRequested = path
+	s.installChan <- path
+
+	response := map[string]interface{}{
+		"success": true,
+		"path":    path,
+		"yaml":    yaml,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// checkSameOriginJSON guards the requests that write files or start the
+// installation against cross-site requests: a page on another site can post a
+// form to the web UI, but not with a JSON body, and not with an Origin that
+// matches Host. Host must name the server by IP address or as localhost, so a
+// DNS-rebound hostname cannot pass as same-origin either.
+func checkSameOriginJSON(r *http.Request) error {
+	if mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";"); strings.TrimSpace(mediaType) != "application/json" {
+		return fmt.Errorf("request must be sent as application/json")
+	}
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host != "localhost" && net.ParseIP(strings.Trim(host, "[]")) == nil {
+		return fmt.Errorf("request must address the web UI by IP address or localhost, not %q", r.Host)
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+			return fmt.Errorf("cross-origin request from %q rejected", origin)
+		}
+	}
+	return nil
+}
+
+// saveConfig validates the config of a save request and writes it to the
+// requested file, returning its absolute path. On failure it writes the error
+// response itself and returns ok false. Callers must hold saveMu.
+func saveConfig(w http.ResponseWriter, r *http.Request) (path, yaml string, ok bool) {
+	var req config.SaveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return "", "", false
+	}
+
+	filename, err := resolveSaveFilename(req.Filename)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", "", false
+	}
+
+	// Normalize and validate before saving
+	config.Normalize(req.Config)
 	errors := config.Validate(req.Config)
 	if len(errors) > 0 {
 		w.WriteHeader(http.StatusBadRequest)
@@ -88,26 +320,73 @@ func handleSave(w http.ResponseWriter, r *http.Request) {
 			Valid:  false,
 			Errors: errors,
 		})
-		return
+		return "", "", false
 	}
 
-	yaml := config.GenerateYAML(req.Config)
+	yaml = config.GenerateYAML(req.Config)
 
-	// Write to specified filename in current working directory
-	if err := os.WriteFile(req.Filename, []byte(yaml), 0644); err != nil {
+	// Write to the filename relative to the current working directory
+	if err := os.WriteFile(filename, []byte(yaml), 0644); err != nil {
 		http.Error(w, "Failed to write file: "+err.Error(), http.StatusInternalServerError)
-		return
+		return "", "", false
 	}
+	lastYAML = yaml
 
 	// Get absolute path for response
 	cwd, _ := os.Getwd()
+	return filepath.Join(cwd, filename), yaml, true
+}
 
-	response := map[string]interface{}{
-		"success": true,
-		"path":    cwd + "/" + req.Filename,
-		"yaml":    yaml,
+// resolveSaveFilename returns the file to write for a save request. An empty
+// name means bloom.yaml; other names must be .yaml/.yml files that stay within
+// the working directory, also after resolving symlinks, so one UI session can
+// save configs for several nodes.
+func resolveSaveFilename(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "bloom.yaml", nil
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("filename %q must be a relative path within the working directory", name)
 	}
+	name = filepath.Clean(name)
+	if ext := filepath.Ext(name); ext != ".yaml" && ext != ".yml" {
+		return "", fmt.Errorf("filename %q must end in .yaml or .yml", name)
+	}
+	if dir := filepath.Dir(name); dir != "." {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "", fmt.Errorf("directory %q does not exist in the working directory", dir)
+		}
+	}
+	if err := checkWithinWorkDir(name); err != nil {
+		return "", fmt.Errorf("filename %q: %w", name, err)
+	}
+	return name, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+// checkWithinWorkDir rejects a local path that a symlinked directory, or a
+// symlink at the path itself, points outside the working directory. A
+// dangling symlink is rejected as well, since writing would create its target.
+func checkWithinWorkDir(name string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(cwd)
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(cwd, name)
+	if info, err := os.Lstat(target); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		// Only the directory can redirect a missing or regular file
+		target = filepath.Dir(target)
+	}
+	resolved, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		return fmt.Errorf("resolve symlinks: %w", err)
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || (rel != "." && !filepath.IsLocal(rel)) {
+		return fmt.Errorf("resolves to %s, outside the working directory", resolved)
+	}
+	return nil
 }
@@ -0,0 +1,303 @@
+package webui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/silogen/cluster-bloom/pkg/config"
+)
+
+func TestHandleDownload(t *testing.T) {
+	t.Cleanup(func() { lastYAML = "" })
+
+	lastYAML = ""
+	rec := httptest.NewRecorder()
+	handleDownload(rec, httptest.NewRequest(http.MethodGet, "/api/config/download", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("before generating: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	lastYAML = "DOMAIN: cluster.example.com\nFIRST_NODE: true\n"
+	rec = httptest.NewRecorder()
+	handleDownload(rec, httptest.NewRequest(http.MethodGet, "/api/config/download", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="bloom.yaml"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	if rec.Body.String() != lastYAML {
+		t.Errorf("body = %q, want %q", rec.Body.String(), lastYAML)
+	}
+
+	rec = httptest.NewRecorder()
+	handleDownload(rec, httptest.NewRequest(http.MethodPost, "/api/config/download", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandleImport(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantConfig  map[string]any
+		wantUnknown []string
+		wantError   string
+	}{
+		{
+			name:       "normalizes and keeps known keys",
+			body:       "DOMAIN: https://cluster.example.com/\nFIRST_NODE: true\nGPU_NODE: \"false\"\nSOMETHING_ELSE: 1\n",
+			wantStatus: http.StatusOK,
+			wantConfig: map[string]any{
+				"DOMAIN":     "cluster.example.com",
+				"FIRST_NODE": true,
+				"GPU_NODE":   false,
+			},
+			wantUnknown: []string{"SOMETHING_ELSE"},
+		},
+		{
+			name:       "empty document",
+			body:       "",
+			wantStatus: http.StatusOK,
+			wantConfig: map[string]any{},
+		},
+		{
+			name:       "invalid YAML",
+			body:       "DOMAIN: [unclosed\n",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "Invalid YAML",
+		},
+		{
+			name:       "not a mapping",
+			body:       "- just\n- a list\n",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "Invalid YAML",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handleImport(rec, httptest.NewRequest(http.MethodPost, "/api/config/import", strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantError != "" {
+				if !strings.Contains(rec.Body.String(), tt.wantError) {
+					t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.wantError)
+				}
+				return
+			}
+
+			var resp struct {
+				Config  map[string]any `json:"config"`
+				Unknown []string       `json:"unknown"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(resp.Config, tt.wantConfig) {
+				t.Errorf("config = %v, want %v", resp.Config, tt.wantConfig)
+			}
+			if len(resp.Unknown) != len(tt.wantUnknown) || (len(tt.wantUnknown) > 0 && !reflect.DeepEqual(resp.Unknown, tt.wantUnknown)) {
+				t.Errorf("unknown = %v, want %v", resp.Unknown, tt.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestFormValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		argType string
+		value   any
+		want    any
+	}{
+		{name: "bool", argType: "bool", value: true, want: true},
+		{name: "bool from string", argType: "bool", value: "false", want: false},
+		{name: "string", argType: "string", value: "10.0.0.1", want: "10.0.0.1"},
+		{name: "number as string", argType: "string", value: 3, want: "3"},
+		{name: "null", argType: "string", value: nil, want: ""},
+		{name: "list for a string field", argType: "string", value: []any{"/dev/sdb", "/dev/sdc"}, want: "/dev/sdb,/dev/sdc"},
+		{name: "array field keeps its items", argType: "array", value: []any{"a"}, want: []any{"a"}},
+		{name: "map as pairs", argType: "map", value: map[string]any{"vm.swappiness": 10, "fs.file-max": "1000"}, want: "fs.file-max=1000, vm.swappiness=10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formValue(tt.argType, tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("formValue(%q, %v) = %#v, want %#v", tt.argType, tt.value, got, tt.want)
+			}
+		})
+	}
+
+	// Values parsed from YAML use the same conversions
+	values, unknown := formValues(config.Config{"FIRST_NODE": "true", "NOPE": 1}, []config.Argument{{Key: "FIRST_NODE", Type: "bool"}})
+	if values["FIRST_NODE"] != true || len(unknown) != 1 || unknown[0] != "NOPE" {
+		t.Errorf("formValues() = %v, %v", values, unknown)
+	}
+}
+
+func TestResolveSaveFilename(t *testing.T) {
+	outside := t.TempDir()
+	t.Chdir(t.TempDir())
+	for _, dir := range []string{"nodes", "nodes/gpu"} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"nodes/bloom.yaml", filepath.Join(outside, "bloom.yaml")} {
+		if err := os.WriteFile(file, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"escape":        outside,
+		"nodes-link":    "nodes",
+		"out.yaml":      filepath.Join(outside, "bloom.yaml"),
+		"inside.yaml":   "nodes/bloom.yaml",
+		"nodes/up-link": "..",
+		"dangling.yaml": "nodes/missing.yaml",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", file: "  ", want: "bloom.yaml"},
+		{name: "plain", file: "node2.yaml", want: "node2.yaml"},
+		{name: "yml", file: "node2.yml", want: "node2.yml"},
+		{name: "subdirectory", file: "nodes/gpu/node3.yaml", want: "nodes/gpu/node3.yaml"},
+		{name: "cleaned", file: "nodes/../node2.yaml", want: "node2.yaml"},
+		{name: "wrong extension", file: "node2.json", wantErr: true},
+		{name: "missing directory", file: "missing/node2.yaml", wantErr: true},
+		{name: "parent", file: "../node2.yaml", wantErr: true},
+		{name: "parent after subdirectory", file: "nodes/../../node2.yaml", wantErr: true},
+		{name: "absolute", file: filepath.Join(outside, "node2.yaml"), wantErr: true},
+		{name: "root", file: "/etc/bloom.yaml", wantErr: true},
+		{name: "symlinked dir inside", file: "nodes-link/node2.yaml", want: "nodes-link/node2.yaml"},
+		{name: "symlinked dir to parent", file: "nodes/up-link/node2.yaml", want: "nodes/up-link/node2.yaml"},
+		{name: "symlinked dir escapes", file: "escape/node2.yaml", wantErr: true},
+		{name: "symlinked file inside", file: "inside.yaml", want: "inside.yaml"},
+		{name: "symlinked file escapes", file: "out.yaml", wantErr: true},
+		{name: "dangling symlink", file: "dangling.yaml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSaveFilename(tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveSaveFilename(%q) error = %v, wantErr %v", tt.file, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveSaveFilename(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSameOriginJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        string
+		contentType string
+		origin      string
+		wantErr     bool
+	}{
+		{name: "same origin", host: "127.0.0.1:62078", contentType: "application/json", origin: "http://127.0.0.1:62078"},
+		{name: "charset parameter", host: "127.0.0.1:62078", contentType: "application/json; charset=utf-8"},
+		{name: "no origin (curl)", host: "localhost:62078", contentType: "application/json"},
+		{name: "ipv6 host", host: "[::1]:62078", contentType: "application/json", origin: "http://[::1]:62078"},
+		{name: "form post", host: "127.0.0.1:62078", contentType: "application/x-www-form-urlencoded", wantErr: true},
+		{name: "text/plain post", host: "127.0.0.1:62078", contentType: "text/plain", wantErr: true},
+		{name: "no content type", host: "127.0.0.1:62078", wantErr: true},
+		{name: "cross origin", host: "127.0.0.1:62078", contentType: "application/json", origin: "http://evil.example.com", wantErr: true},
+		{name: "other port", host: "127.0.0.1:62078", contentType: "application/json", origin: "http://127.0.0.1:8080", wantErr: true},
+		{name: "rebound hostname", host: "evil.example.com:62078", contentType: "application/json", origin: "http://evil.example.com:62078", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/install", nil)
+			req.Host = tt.host
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if err := checkSameOriginJSON(req); (err != nil) != tt.wantErr {
+				t.Errorf("checkSameOriginJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInstallRefusal(t *testing.T) {
+	t.Cleanup(func() { isRoot = func() bool { return os.Getuid() == 0 } })
+
+	tests := []struct {
+		name   string
+		root   bool
+		bind   string
+		token  string
+		reason string // Substring of the refusal; empty means allowed
+	}{
+		{name: "loopback", root: true, bind: DefaultBindAddr},
+		{name: "remote with token", root: true, bind: "0.0.0.0", token: "secret"},
+		{name: "remote without token", root: true, bind: "0.0.0.0", reason: "requires WEBUI_AUTH_TOKEN"},
+		{name: "not root", bind: DefaultBindAddr, reason: "requires root privileges"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isRoot = func() bool { return tt.root }
+			s := &Server{BindAddr: tt.bind, AuthToken: tt.token, installChan: make(chan string, 1)}
+			if got := s.installRefusal(); (tt.reason == "") != (got == "") || !strings.Contains(got, tt.reason) {
+				t.Errorf("installRefusal() = %q, want %q", got, tt.reason)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/install", strings.NewReader(`{"config":{}}`))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			s.handleInstall(rec, req)
+			if tt.reason != "" && (rec.Code != http.StatusForbidden || len(s.installChan) != 0) {
+				t.Errorf("status = %d, want %d without starting the installation", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestHandleInstallRejectsCrossSiteForm(t *testing.T) {
+	isRoot = func() bool { return true }
+	t.Cleanup(func() { isRoot = func() bool { return os.Getuid() == 0 } })
+
+	s := &Server{BindAddr: DefaultBindAddr, installChan: make(chan string, 1)}
+	req := httptest.NewRequest(http.MethodPost, "/api/install", strings.NewReader(`{"config":{}}`))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Origin", "http://evil.example.com")
+	rec := httptest.NewRecorder()
+	s.handleInstall(rec, req)
+
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "application/json") {
+		t.Errorf("status = %d, body %q, want %d for the content type", rec.Code, rec.Body.String(), http.StatusForbidden)
+	}
+	if s.installRequested != "" || len(s.installChan) != 0 {
+		t.Error("cross-site request started the installation")
+	}
+}
@@ -3,6 +3,7 @@ package webui
 import (
 	"bufio"
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"io/fs"
 	"log"
@@ -10,20 +11,33 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 )
 
+// DefaultBindAddr keeps the web UI reachable from the local node only
+const DefaultBindAddr = "127.0.0.1"
+
 // Server represents the web UI server
 type Server struct {
 	Port          int
-	PortSpecified bool // true if user explicitly specified port via --port flag
+	PortSpecified bool     // true if user explicitly specified port via --port flag
+	InstallConfig string   // config saved via /api/install; install it after Start returns
+	BindAddr      string   // IP address to listen on; empty means DefaultBindAddr
+	AllowCIDRs    []string // remote networks allowed besides loopback when BindAddr is not loopback
+	AuthToken     string   // when set, /api/* requests must present it
+	allowedNets   []*net.IPNet
 	server        *http.Server
+	installChan   chan string
+	// installRequested is the config path of the accepted install request,
+	// guarded by saveMu
+	installRequested string
 }
 
 // findAvailablePort finds an available port starting from startPort
-func findAvailablePort(startPort int) int {
+func findAvailablePort(bindAddr string, startPort int) int {
 	for port := startPort; port < startPort+100; port++ {
-		ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		ln, err := net.Listen("tcp", net.JoinHostPort(bindAddr, fmt.Sprint(port)))
 		if err == nil {
 			ln.Close()
 			return port
@@ -33,8 +47,8 @@ func findAvailablePort(startPort int) int {
 }
 
 // isPortAvailable checks if a specific port is available
-func isPortAvailable(port int) bool {
-	ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+func isPortAvailable(bindAddr string, port int) bool {
+	ln, err := net.Listen("tcp", net.JoinHostPort(bindAddr, fmt.Sprint(port)))
 	if err != nil {
 		return false
 	}
@@ -42,16 +56,117 @@ func isPortAvailable(port int) bool {
 	return true
 }
 
+// parseAccess checks BindAddr and AllowCIDRs and fills in allowedNets
+func (s *Server) parseAccess() error {
+	if s.BindAddr == "" {
+		s.BindAddr = DefaultBindAddr
+	}
+	if net.ParseIP(s.BindAddr) == nil {
+		return fmt.Errorf("bind address %q is not an IP address", s.BindAddr)
+	}
+	for _, cidr := range s.AllowCIDRs {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return fmt.Errorf("invalid allowed CIDR %q: %w", cidr, err)
+		}
+		s.allowedNets = append(s.allowedNets, ipNet)
+	}
+	return nil
+}
+
+// isLoopbackBind reports whether the server only listens on loopback
+func (s *Server) isLoopbackBind() bool {
+	return net.ParseIP(s.BindAddr).IsLoopback()
+}
+
+// isRoot reports whether bloom runs as root, which the installation needs
+var isRoot = func() bool { return os.Getuid() == 0 }
+
+// installRefusal returns why Save and Install is disabled, or "" when it is
+// allowed. It starts a destructive installation, so it needs root and, unless
+// only local clients can reach the server, an auth token.
+func (s *Server) installRefusal() string {
+	if !isRoot() {
+		return "installation requires root privileges: restart the web UI with sudo bloom webui"
+	}
+	if !s.isLoopbackBind() && s.AuthToken == "" {
+		return "installation from a web UI listening on " + s.BindAddr + " requires WEBUI_AUTH_TOKEN"
+	}
+	return ""
+}
+
+// clientAllowed reports whether a request from remoteAddr may use the web UI:
+// loopback clients always may, others only from an allowed network
+func (s *Server) clientAllowed(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	for _, ipNet := range s.allowedNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedClientsOnly rejects requests from clients outside the allowlist, so
+// a non-loopback bind never exposes the config generator to the whole network
+func (s *Server) allowedClientsOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.clientAllowed(r.RemoteAddr) {
+			log.Printf("Rejected web UI request from %s", r.RemoteAddr)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireToken rejects /api/* requests that do not carry AuthToken, either as
+// an "Authorization: Bearer" header or as a token query parameter. The static
+// pages stay public so the browser can load the UI and forward ?token= itself.
+func (s *Server) requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.AuthToken == "" || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := r.URL.Query().Get("token")
+		if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+			token = strings.TrimPrefix(header, "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.AuthToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="bloom"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // Start starts the web UI server
 func (s *Server) Start() error {
+	if err := s.parseAccess(); err != nil {
+		return err
+	}
+
 	// If port was explicitly specified, fail if not available
 	if s.PortSpecified {
-		if !isPortAvailable(s.Port) {
+		if !isPortAvailable(s.BindAddr, s.Port) {
 			return fmt.Errorf("port %d is already in use", s.Port)
 		}
 	} else {
 		// Auto-find available port starting from default
-		availablePort := findAvailablePort(s.Port)
+		availablePort := findAvailablePort(s.BindAddr, s.Port)
 		if availablePort != s.Port {
 			log.Printf("Port %d is in use, using port %d instead", s.Port, availablePort)
 		}
@@ -70,23 +185,48 @@ func (s *Server) Start() error {
 	http.HandleFunc("/api/schema", handleSchema)
 	http.HandleFunc("/api/generate", handleGenerate)
 	http.HandleFunc("/api/save", handleSave)
+	http.HandleFunc("/api/config/download", handleDownload)
+	http.HandleFunc("/api/config/import", handleImport)
+	s.installChan = make(chan string, 1)
+	http.HandleFunc("/api/install", s.handleInstall)
 	http.Handle("/", fileServer)
 
-	addr := fmt.Sprintf(":%d", s.Port)
-	s.server = &http.Server{Addr: addr}
+	addr := net.JoinHostPort(s.BindAddr, fmt.Sprint(s.Port))
+	s.server = &http.Server{Addr: addr, Handler: s.allowedClientsOnly(s.requireToken(http.DefaultServeMux))}
 
 	// Print startup messages
 	fmt.Printf("🚀 Starting Cluster-Bloom Web Interface...\n")
 	fmt.Printf("\n")
-	fmt.Printf("🌐 Web interface starting on http://127.0.0.1:%d\n", s.Port)
-	fmt.Printf("📊 Configuration interface accessible only from localhost\n")
-	fmt.Printf("🔧 Configure your cluster at http://127.0.0.1:%d\n", s.Port)
-	fmt.Printf("\n")
-	fmt.Printf("🔗 For remote access, create an SSH tunnel:\n")
-	fmt.Printf("   ssh -L %d:127.0.0.1:%d user@remote-server\n", s.Port, s.Port)
-	fmt.Printf("   Then access: http://127.0.0.1:%d\n", s.Port)
+	fmt.Printf("🌐 Web interface starting on http://%s\n", addr)
+	if s.AuthToken != "" {
+		fmt.Printf("🔒 API requests need the WEBUI_AUTH_TOKEN: open http://%s/?token=<token>\n", addr)
+	}
+	if s.isLoopbackBind() {
+		fmt.Printf("📊 Configuration interface accessible only from localhost\n")
+		fmt.Printf("🔧 Configure your cluster at http://%s\n", addr)
+		fmt.Printf("\n")
+		fmt.Printf("🔗 For remote access, create an SSH tunnel:\n")
+		fmt.Printf("   ssh -L %d:%s user@remote-server\n", s.Port, addr)
+		fmt.Printf("   Then access: http://127.0.0.1:%d\n", s.Port)
+	} else {
+		fmt.Printf("\n")
+		fmt.Printf("⚠️  WARNING: the web interface listens on %s, not only on localhost.\n", s.BindAddr)
+		if s.AuthToken == "" {
+			fmt.Printf("⚠️  It serves config generation without authentication, and Save and Install\n")
+			fmt.Printf("⚠️  is disabled; set WEBUI_AUTH_TOKEN to require a token and enable it.\n")
+		}
+		if len(s.allowedNets) == 0 {
+			fmt.Printf("⚠️  No --allow-cidrs given: requests from other hosts are still rejected.\n")
+		} else {
+			fmt.Printf("⚠️  Requests are accepted from localhost and %s only.\n", strings.Join(s.AllowCIDRs, ", "))
+		}
+	}
 	fmt.Printf("\n")
-	fmt.Printf("💡 Press Enter to exit\n")
+	if reason := s.installRefusal(); reason != "" {
+		fmt.Printf("💡 Press Enter to exit. Save and Install is disabled: %s\n", reason)
+	} else {
+		fmt.Printf("💡 Press Enter to exit, or use Save and Install to start the installation\n")
+	}
 
 	// Start server in goroutine
 	errChan := make(chan error, 1)
@@ -121,5 +261,9 @@ func (s *Server) Start() error {
 	case <-exitChan:
 		fmt.Println("   Shutting down server...")
 		return s.server.Shutdown(context.Background())
+	case path := <-s.installChan:
+		s.InstallConfig = path
+		fmt.Printf("   Configuration saved to %s, shutting down server to start installation...\n", path)
+		return s.server.Shutdown(context.Background())
 	}
 }
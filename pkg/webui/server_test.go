@@ -0,0 +1,85 @@
+package webui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireToken(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		token      string
+		path       string
+		header     string
+		remoteAddr string
+		wantStatus int
+	}{
+		{name: "no token configured", path: "/api/schema", wantStatus: http.StatusOK},
+		{name: "bearer header", token: "s3cret", path: "/api/schema", header: "Bearer s3cret", wantStatus: http.StatusOK},
+		{name: "query parameter", token: "s3cret", path: "/api/schema?token=s3cret", wantStatus: http.StatusOK},
+		{name: "missing token", token: "s3cret", path: "/api/save", wantStatus: http.StatusUnauthorized},
+		{name: "wrong bearer token", token: "s3cret", path: "/api/install", header: "Bearer guess", wantStatus: http.StatusUnauthorized},
+		{name: "wrong query token", token: "s3cret", path: "/api/generate?token=guess", wantStatus: http.StatusUnauthorized},
+		{name: "basic auth header", token: "s3cret", path: "/api/schema", header: "Basic czNjcmV0", wantStatus: http.StatusUnauthorized},
+		{name: "static page stays public", token: "s3cret", path: "/index.html", wantStatus: http.StatusOK},
+		{name: "allowlist still applies", token: "s3cret", path: "/api/schema", header: "Bearer s3cret", remoteAddr: "192.0.2.10:40000", wantStatus: http.StatusForbidden},
+		{name: "allowlist rejects before the token check", token: "s3cret", path: "/api/schema", remoteAddr: "192.0.2.10:40000", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{AuthToken: tt.token}
+			if err := s.parseAccess(); err != nil {
+				t.Fatal(err)
+			}
+			handler := s.allowedClientsOnly(s.requireToken(ok))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.RemoteAddr = "127.0.0.1:40000"
+			if tt.remoteAddr != "" {
+				req.RemoteAddr = tt.remoteAddr
+			}
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 response without WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestClientAllowed(t *testing.T) {
+	s := &Server{AllowCIDRs: []string{"10.0.0.0/8", "fd00::/8"}}
+	if err := s.parseAccess(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		remoteAddr string
+		want       bool
+	}{
+		{"127.0.0.1:5000", true},
+		{"[::1]:5000", true},
+		{"10.1.2.3:5000", true},
+		{"[fd00::5]:5000", true},
+		{"192.168.1.5:5000", false},
+		{"garbage", false},
+	}
+	for _, tt := range tests {
+		if got := s.clientAllowed(tt.remoteAddr); got != tt.want {
+			t.Errorf("clientAllowed(%q) = %v, want %v", tt.remoteAddr, got, tt.want)
+		}
+	}
+}